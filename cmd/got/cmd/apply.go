@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply [flags]",
+	Short: "Apply the changes needed for a DNS zone to match a spec",
+	Long: `
+Reads a YAML spec describing the desired records of a zone, and applies
+the changes shown by plan for the zone to match it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		spec, p, changes := planChanges()
+		if len(changes) == 0 {
+			log.Println("Zone is already up to date")
+			return
+		}
+		if err := got.WriteChanges(os.Stdout, changes); err != nil {
+			log.Fatal(err)
+		}
+		ids := applyChanges(p, spec.Zone, changes)
+		switch {
+		case verify:
+			verifyChanges(p, spec.Zone, ids, changes)
+		case wait:
+			waitForChanges(p, ids)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(applyCmd)
+
+	applyCmd.PersistentFlags().StringVarP(
+		&specFile,
+		"file",
+		"f",
+		"",
+		"YAML spec of the zone.",
+	)
+	applyCmd.PersistentFlags().BoolVarP(
+		&prune,
+		"prune",
+		"",
+		false,
+		"Delete records not present in the spec",
+	)
+	addWaitFlags(applyCmd)
+	addVerifyFlags(applyCmd)
+}
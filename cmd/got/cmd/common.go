@@ -1,9 +1,24 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
 )
 
 // connect initializes connection to AWS API
@@ -19,3 +34,772 @@ func connect() *route53.Route53 {
 	}
 	return route53.New(session)
 }
+
+// logChanges logs every change in the list, in order.
+func logChanges(changes []*route53.Change) {
+	for _, change := range changes {
+		log.Printf(
+			"Change %s %s %s",
+			*change.Action,
+			*change.ResourceRecordSet.Name,
+			*change.ResourceRecordSet.Type,
+		)
+	}
+}
+
+// getProvider returns the Provider for the zone selected by the flags.
+func getProvider(zone string) got.Provider {
+	switch viper.GetString("provider") {
+	case "route53":
+		svc := connect()
+		hz, err := got.FindHostedZone(zone, zoneSelector(), svc)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return got.NewRoute53ProviderByID(*hz.Id, svc)
+	case "rfc2136":
+		server := viper.GetString("server")
+		if len(server) <= 0 {
+			log.Fatal("No DNS server specified")
+		}
+		return got.NewRFC2136Provider(
+			zone,
+			server,
+			viper.GetString("tsig-name"),
+			viper.GetString("tsig-secret"),
+			viper.GetString("tsig-algorithm"),
+		)
+	}
+	log.Fatalf("Unknown provider %s", viper.GetString("provider"))
+	return nil
+}
+
+// zoneSelector returns the selector of hosted zones described by the
+// flags.
+func zoneSelector() *got.ZoneSelector {
+	s := &got.ZoneSelector{VPCID: viper.GetString("vpc")}
+	if viper.IsSet("private") {
+		s.Private = aws.Bool(viper.GetBool("private"))
+	}
+	return s
+}
+
+var allZones bool
+
+// addAllZonesFlag adds the flag to work on every hosted zone to the command.
+func addAllZonesFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(
+		&allZones,
+		"all-zones",
+		"",
+		false,
+		"Work on every hosted zone in the account instead of --zone.",
+	)
+}
+
+// selectedZone is a zone to work on, along with its provider.
+type selectedZone struct {
+	name     string
+	provider got.Provider
+}
+
+// selectedZones returns the zone named by --zone or, with --all-zones,
+// every hosted zone in the account matching --private and --vpc.
+func selectedZones() (zones []selectedZone) {
+	if !allZones {
+		if len(zoneName) <= 0 {
+			log.Fatal("No zone name specified")
+		}
+		return []selectedZone{{name: zoneName, provider: getProvider(zoneName)}}
+	}
+	if viper.GetString("provider") != "route53" {
+		log.Fatal("--all-zones is only supported by the route53 provider")
+	}
+	svc := connect()
+	hostedZones, err := got.GetHostedZones(zoneSelector(), svc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, hz := range hostedZones {
+		zones = append(zones, selectedZone{
+			name:     *hz.Name,
+			provider: got.NewRoute53ProviderByID(*hz.Id, svc),
+		})
+	}
+	return
+}
+
+// journalPath returns the path of the journal of changes.
+func journalPath() string {
+	if path := viper.GetString("journal"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return filepath.Join(home, ".got", "journal.jsonl")
+}
+
+// journaledProvider is a got.Provider recording every change applied
+// through it in the journal, along with the previous state of the record
+// sets touched.
+type journaledProvider struct {
+	got.Provider
+	zone string
+}
+
+// Apply records the changes in the journal, applies them through the
+// wrapped provider and records them again along with the IDs of the
+// batches applied. Nothing is applied if the journal can't be written.
+func (p *journaledProvider) Apply(changes []*route53.Change) ([]string, error) {
+	current, err := p.List()
+	if err != nil {
+		return nil, err
+	}
+	zoneID := ""
+	if r53, ok := p.Provider.(*got.Route53Provider); ok {
+		zoneID = r53.ZoneID
+	}
+	journal := got.NewJournal(journalPath())
+	entry := got.NewJournalEntry(p.zone, zoneID, changes, current)
+	if err = journal.Record(entry); err != nil {
+		return nil, fmt.Errorf("unable to record changes in journal: %s", err)
+	}
+	ids, err := p.Provider.Apply(changes)
+	if len(ids) > 0 {
+		entry.SetChangeIDs(ids)
+		if jerr := journal.Record(entry); jerr != nil {
+			return ids, fmt.Errorf(
+				"unable to record change %s in journal: %s",
+				entry.ID,
+				jerr,
+			)
+		}
+	}
+	return ids, err
+}
+
+// applyChanges applies the changes to the zone through the provider,
+// recording them in the journal, and returns the ID of every batch applied.
+func applyChanges(
+	p got.Provider,
+	zone string,
+	changes []*route53.Change,
+) []string {
+	ids, err := (&journaledProvider{Provider: p, zone: zone}).Apply(changes)
+	logChangeIDs(ids)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return ids
+}
+
+// logChangeIDs logs the ID of every batch applied.
+func logChangeIDs(ids []string) {
+	for _, id := range ids {
+		log.Printf("Change %s submitted", id)
+	}
+}
+
+var waitTimeout time.Duration
+
+// addWaitFlags adds the flags to wait for the changes to be applied to the
+// command.
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(
+		&wait,
+		"wait",
+		"",
+		false,
+		"Don't return until operation is completed",
+	)
+	cmd.PersistentFlags().DurationVarP(
+		&waitTimeout,
+		"wait-timeout",
+		"",
+		10*time.Minute,
+		"Maximum time to wait for changes to be applied. Unlimited if 0.",
+	)
+}
+
+// waitContext returns the context for waiting, which is cancelled on
+// interrupt or after --wait-timeout, if set.
+func waitContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if waitTimeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, waitTimeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// newWaiter returns the Waiter logging the progress of every change.
+func newWaiter() *got.Waiter {
+	w := got.NewWaiter()
+	w.Progress = func(p got.WaitProgress) {
+		elapsed := p.Elapsed.Round(time.Second)
+		if p.Err != nil {
+			log.Printf("Change %s unknown after %s: %s", p.ID, elapsed, p.Err)
+			return
+		}
+		log.Printf("Change %s %s after %s", p.ID, p.Status, elapsed)
+	}
+	return w
+}
+
+// waitForChanges waits until every batch applied is completed.
+func waitForChanges(p got.Provider, ids []string) {
+	ctx, cancel := waitContext()
+	defer cancel()
+	if err := newWaiter().Wait(ctx, p, ids...); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("All changes applied")
+}
+
+var filterExpression string
+
+// addFilterFlag adds the flag selecting records with a filter expression to
+// the command.
+func addFilterFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&filterExpression,
+		"filter",
+		"",
+		"",
+		"Expression selecting records, e.g. 'type in (A,CNAME) and ttl > 300'.",
+	)
+}
+
+// filterRecordSets returns the record sets in list passing every filter
+// expression not empty.
+func filterRecordSets(
+	list []*route53.ResourceRecordSet,
+	expressions ...string,
+) []*route53.ResourceRecordSet {
+	for _, expression := range expressions {
+		if expression == "" {
+			continue
+		}
+		f, err := got.ParseFilter(expression)
+		if err != nil {
+			log.Fatal(err)
+		}
+		list = f.Select(list)
+	}
+	return list
+}
+
+var inputFile, inputFormat string
+
+// addInputFlags adds the flags reading records in bulk to the command.
+func addInputFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&inputFile,
+		"input",
+		"i",
+		"",
+		"CSV or JSON file with the records to change, or - for standard input.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&inputFormat,
+		"input-format",
+		"",
+		"",
+		"Format of the input: csv or json. Defaults to json for .json files, and csv otherwise.",
+	)
+}
+
+// readInput returns the records in the input file.
+func readInput() []got.Record {
+	in := os.Stdin
+	if inputFile != "-" {
+		file, err := os.Open(inputFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		in = file
+	}
+	format := inputFormat
+	if format == "" {
+		format = "csv"
+		if strings.EqualFold(filepath.Ext(inputFile), ".json") {
+			format = "json"
+		}
+	}
+	records, err := got.ReadRecords(in, format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return records
+}
+
+// bulkChanges returns the changes of the records read in bulk, exiting
+// after logging every invalid record if there is any.
+func bulkChanges(changes []*route53.Change, errs []error) []*route53.Change {
+	for _, err := range errs {
+		log.Println(err)
+	}
+	if len(errs) > 0 {
+		log.Fatalf("%d invalid records, nothing changed", len(errs))
+	}
+	return changes
+}
+
+// applyBulkChanges applies the changes as applyChanges does, logging the
+// batch each record set went in, or that it wasn't applied if its batch
+// or any before it failed.
+func applyBulkChanges(
+	p got.Provider,
+	zone string,
+	changes []*route53.Change,
+) []string {
+	ids, err := (&journaledProvider{Provider: p, zone: zone}).Apply(changes)
+	for i, batch := range got.SplitChanges(changes) {
+		for _, change := range batch {
+			rrs := change.ResourceRecordSet
+			id := ""
+			switch {
+			case i < len(ids):
+				id = ids[i]
+			case err == nil && len(ids) > 0:
+				// Providers applying every batch at once return a single ID.
+				id = ids[len(ids)-1]
+			}
+			if id == "" {
+				log.Printf("Record %s %s: not applied", *rrs.Name, *rrs.Type)
+				continue
+			}
+			log.Printf(
+				"Record %s %s: %s in change %s",
+				*rrs.Name,
+				*rrs.Type,
+				*change.Action,
+				id,
+			)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ids
+}
+
+var diffFormat string
+var noColor bool
+
+// addDiffFlags adds the flags choosing how dry runs show changes to the
+// command.
+func addDiffFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&diffFormat,
+		"diff-format",
+		"",
+		"text",
+		"Format of the changes shown on dry runs: text or json.",
+	)
+	cmd.PersistentFlags().BoolVarP(
+		&noColor,
+		"no-color",
+		"",
+		false,
+		"Don't colorize the changes shown on dry runs.",
+	)
+}
+
+// previewChanges writes to stdout the record sets in current affected by
+// the changes as they are and as they would be after them.
+func previewChanges(
+	current []*route53.ResourceRecordSet,
+	changes []*route53.Change,
+) {
+	diffs := got.PreviewChanges(changes, current)
+	var err error
+	switch diffFormat {
+	case "text":
+		err = got.WriteDiffs(os.Stdout, diffs, !noColor && isTerminal(os.Stdout))
+	case "json":
+		err = got.WriteDiffsJSON(os.Stdout, diffs)
+	default:
+		log.Fatalf("Unknown diff format %s", diffFormat)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// isTerminal returns whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+var yes bool
+
+// addYesFlag adds the flag skipping confirmations to the command.
+func addYesFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(
+		&yes,
+		"yes",
+		"y",
+		false,
+		"Don't ask for confirmation.",
+	)
+}
+
+// confirmChanges shows the changes, along with those to the reverse
+// zones, and asks for confirmation before they are applied, unless
+// confirmations are skipped. It exits if they aren't confirmed, or there's
+// no terminal to ask in.
+func confirmChanges(
+	current []*route53.ResourceRecordSet,
+	changes []*route53.Change,
+	ptr []*got.ZoneChanges,
+) {
+	if yes {
+		return
+	}
+	if !isTerminal(os.Stdin) {
+		log.Fatal("Not running in a terminal, use --yes to confirm the changes")
+	}
+	previewChanges(current, changes)
+	previewPTRChanges(ptr)
+	fmt.Print("Apply these changes? [y/N] ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		log.Fatal(err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+	default:
+		log.Fatal("Changes not confirmed")
+	}
+}
+
+var syncPTR bool
+
+// addPTRFlag adds the flag keeping PTR records in sync to the command.
+func addPTRFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(
+		&syncPTR,
+		"ptr",
+		"",
+		false,
+		"Keep the PTR records of the A and AAAA records in sync.",
+	)
+}
+
+// ptrChanges returns the changes to the reverse zones of the account
+// keeping their PTR records in sync with the changes to the zone, whose
+// record sets are currently those in current, if --ptr is given.
+func ptrChanges(
+	current []*route53.ResourceRecordSet,
+	changes []*route53.Change,
+) []*got.ZoneChanges {
+	if !syncPTR {
+		return nil
+	}
+	if viper.GetString("provider") != "route53" {
+		log.Fatal("--ptr is only supported by the route53 provider")
+	}
+	reverse, err := got.NewReverseZones(zoneSelector(), connect())
+	if err != nil {
+		log.Fatal(err)
+	}
+	ptr, err := reverse.PTRChanges(changes, current)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ptr
+}
+
+// previewPTRChanges writes to stdout the changes to every reverse zone, as
+// previewChanges does.
+func previewPTRChanges(ptr []*got.ZoneChanges) {
+	for _, zc := range ptr {
+		fmt.Printf("Zone %s\n", *zc.Zone.Name)
+		previewChanges(zc.Current, zc.Changes)
+	}
+}
+
+// applyPTRChanges applies the changes to every reverse zone, waiting for
+// them to be applied with --wait.
+func applyPTRChanges(ptr []*got.ZoneChanges) {
+	svc := connect()
+	for _, zc := range ptr {
+		p := got.NewRoute53ProviderByID(*zc.Zone.Id, svc)
+		logChanges(zc.Changes)
+		ids := applyChanges(p, *zc.Zone.Name, zc.Changes)
+		if wait {
+			waitForChanges(p, ids)
+		}
+	}
+}
+
+var verify bool
+var resolvers []string
+var dnsPort string
+
+// addVerifyFlags adds the flags to verify the answers of DNS servers after
+// applying changes to the command.
+func addVerifyFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(
+		&verify,
+		"verify",
+		"",
+		false,
+		"Wait for the changes and check the name servers answer with them",
+	)
+	addResolverFlags(cmd)
+}
+
+// addResolverFlags adds the flags selecting the DNS servers to query to the
+// command.
+func addResolverFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVarP(
+		&resolvers,
+		"resolver",
+		"",
+		[]string{},
+		"Public resolver to check as well. Can be repeated.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&dnsPort,
+		"dns-port",
+		"",
+		"53",
+		"Port to query name servers and resolvers on.",
+	)
+}
+
+// verifyRecordSets queries the name servers the zone is delegated to, and
+// the resolvers if any, for the record sets expected, logging the result of
+// every query. It returns whether every server answered as expected.
+func verifyRecordSets(
+	zone string,
+	expected []*route53.ResourceRecordSet,
+) bool {
+	r := got.NewDNSResolver(dnsPort)
+	servers, err := got.DelegatedNameServers(r, delegationResolver(), zone)
+	if err != nil {
+		log.Fatal(err)
+	}
+	verifications := got.VerifyRecordSets(r, servers, expected, true)
+	verifications = append(
+		verifications,
+		got.VerifyRecordSets(r, resolvers, expected, false)...,
+	)
+	ok := true
+	for _, v := range verifications {
+		log.Println(v)
+		ok = ok && v.Match
+	}
+	return ok
+}
+
+// delegationResolver returns the resolver to look up the delegation of
+// zones through, the first one given or else the first of the system.
+func delegationResolver() string {
+	if len(resolvers) > 0 {
+		return resolvers[0]
+	}
+	system, err := got.SystemResolvers()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(system) == 0 {
+		log.Fatal("No resolvers configured")
+	}
+	return system[0]
+}
+
+// verifyChanges waits for the changes to be applied and checks the name
+// servers of the zone answer with them, exiting if any doesn't.
+func verifyChanges(
+	p got.Provider,
+	zone string,
+	ids []string,
+	changes []*route53.Change,
+) {
+	waitForChanges(p, ids)
+	if !verifyRecordSets(zone, got.ExpectedRecordSets(changes)) {
+		log.Fatal("Changes aren't answered by every server yet")
+	}
+}
+
+var setIdentifier, region, failover, healthCheckID string
+var geoContinent, geoCountry, geoSubdivision string
+var aliasTarget, aliasZoneID string
+var weight int64
+var evaluateTargetHealth bool
+
+// addSetIdentifierFlag adds the flag to tell apart records with the same
+// name and type to the command.
+func addSetIdentifierFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&setIdentifier,
+		"set-identifier",
+		"",
+		"",
+		"Identifier of the record among those with the same name and type.",
+	)
+}
+
+// addRoutingFlags adds the flags describing routing policies and alias
+// targets to the command.
+func addRoutingFlags(cmd *cobra.Command) {
+	addSetIdentifierFlag(cmd)
+	cmd.PersistentFlags().Int64VarP(
+		&weight,
+		"weight",
+		"",
+		-1,
+		"Weight of the record, for weighted routing.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&region,
+		"region",
+		"",
+		"",
+		"AWS region of the record, for latency routing.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&failover,
+		"failover",
+		"",
+		"",
+		"PRIMARY or SECONDARY, for failover routing.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&geoContinent,
+		"geo-continent",
+		"",
+		"",
+		"Continent code, for geolocation routing.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&geoCountry,
+		"geo-country",
+		"",
+		"",
+		"Country code, or * for the default location, for geolocation routing.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&geoSubdivision,
+		"geo-subdivision",
+		"",
+		"",
+		"Subdivision code, for geolocation routing.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&healthCheckID,
+		"health-check-id",
+		"",
+		"",
+		"ID or name of the health check to associate with the record.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&aliasTarget,
+		"alias-target",
+		"",
+		"",
+		"DNS name the alias record points to.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&aliasZoneID,
+		"alias-zone-id",
+		"",
+		"",
+		"Hosted zone ID of the alias target.",
+	)
+	cmd.PersistentFlags().BoolVarP(
+		&evaluateTargetHealth,
+		"evaluate-target-health",
+		"",
+		false,
+		"Whether the alias record inherits the health of its target.",
+	)
+}
+
+// resolveHealthCheck replaces the health check name given by the flags, if
+// any, with the health check ID.
+func resolveHealthCheck() {
+	if healthCheckID == "" || viper.GetString("provider") != "route53" {
+		return
+	}
+	check, err := got.FindHealthCheck(healthCheckID, connect())
+	if err != nil {
+		log.Fatal(err)
+	}
+	healthCheckID = *check.Id
+}
+
+// routingFlags are the flags added by addRoutingFlags.
+var routingFlags = []string{
+	"set-identifier",
+	"weight",
+	"region",
+	"failover",
+	"geo-continent",
+	"geo-country",
+	"geo-subdivision",
+	"health-check-id",
+	"alias-target",
+	"alias-zone-id",
+	"evaluate-target-health",
+}
+
+// rejectRoutingFlags exits if any routing or alias flag was given to the
+// command along with --input, as neither is supported in bulk.
+func rejectRoutingFlags(cmd *cobra.Command) {
+	for _, flag := range routingFlags {
+		if cmd.Flags().Changed(flag) {
+			log.Fatalf("--%s can't be used along with --input", flag)
+		}
+	}
+}
+
+// routing returns the Routing described by the flags, or nil if none of
+// them was specified.
+func routing() *got.Routing {
+	r := &got.Routing{
+		SetIdentifier: setIdentifier,
+		Region:        region,
+		Failover:      failover,
+		HealthCheckID: healthCheckID,
+	}
+	if weight >= 0 {
+		r.Weight = aws.Int64(weight)
+	}
+	if geoContinent != "" || geoCountry != "" || geoSubdivision != "" {
+		r.GeoLocation = &route53.GeoLocation{}
+		if geoContinent != "" {
+			r.GeoLocation.ContinentCode = aws.String(geoContinent)
+		}
+		if geoCountry != "" {
+			r.GeoLocation.CountryCode = aws.String(geoCountry)
+		}
+		if geoSubdivision != "" {
+			r.GeoLocation.SubdivisionCode = aws.String(geoSubdivision)
+		}
+	}
+	if aliasTarget != "" {
+		if aliasZoneID == "" {
+			log.Fatal("No alias target hosted zone ID specified")
+		}
+		r.AliasTarget = &route53.AliasTarget{
+			DNSName:              aws.String(aliasTarget),
+			HostedZoneId:         aws.String(aliasZoneID),
+			EvaluateTargetHealth: aws.Bool(evaluateTargetHealth),
+		}
+	}
+	if r.IsSimple() {
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var fromZone, toZone, conflict string
+
+// copyCmd represents the copy command
+var copyCmd = &cobra.Command{
+	Use:   "copy [flags]",
+	Short: "Copy records from a DNS zone to another one",
+	Long: `
+Copies the records of the zone --from, or those selected by --filter, into
+the zone --to. The zone suffix is rewritten in the names of the records
+and in the targets of CNAME, MX, SRV and alias records within the zone.
+The SOA and the NS records at the apex of the zone are never copied, nor
+are the NS records delegating its subdomains, as their name servers serve
+the subdomains of --from. Subdomains of --to must be delegated separately.
+
+Records already in the destination with the same values are left alone.
+Those with different values are skipped, overwritten or make the whole
+copy fail depending on --conflict.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(fromZone) <= 0 {
+			log.Fatal("No source zone specified")
+		}
+		if len(toZone) <= 0 {
+			log.Fatal("No destination zone specified")
+		}
+		c, err := got.NewZoneCopy(fromZone, toZone, conflict)
+		if err != nil {
+			log.Fatal(err)
+		}
+		from := getProvider(fromZone)
+		to := getProvider(toZone)
+		if p, ok := from.(*got.Route53Provider); ok {
+			c.FromZoneID = p.ZoneID
+		}
+		if p, ok := to.(*got.Route53Provider); ok {
+			c.ToZoneID = p.ZoneID
+		}
+		source, err := from.List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		current, err := to.List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		changes, skipped, err := c.Changes(
+			filterRecordSets(source, filterExpression),
+			current,
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, rrs := range skipped {
+			log.Printf("Record %s %s already exists, skipped", *rrs.Name, *rrs.Type)
+		}
+		if len(changes) == 0 {
+			log.Println("Nothing to copy")
+			return
+		}
+		if err = got.WriteChanges(os.Stdout, changes); err != nil {
+			log.Fatal(err)
+		}
+		if !dryrun {
+			ids := applyChanges(to, toZone, changes)
+			if wait {
+				waitForChanges(to, ids)
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(copyCmd)
+
+	copyCmd.PersistentFlags().BoolVarP(
+		&dryrun,
+		"dryrun",
+		"",
+		false,
+		"Don't really do anything",
+	)
+	copyCmd.PersistentFlags().StringVarP(
+		&fromZone,
+		"from",
+		"",
+		"",
+		"Name of the zone to copy records from.",
+	)
+	copyCmd.PersistentFlags().StringVarP(
+		&toZone,
+		"to",
+		"",
+		"",
+		"Name of the zone to copy records to.",
+	)
+	copyCmd.PersistentFlags().StringVarP(
+		&conflict,
+		"conflict",
+		"",
+		got.ConflictFail,
+		"What to do with records existing with other values: skip, overwrite or fail.",
+	)
+	addFilterFlag(copyCmd)
+	addWaitFlags(copyCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var stateFile string
+
+// cutoverCmd represents the cutover command
+var cutoverCmd = &cobra.Command{
+	Use:   "cutover [flags] <destination> ...",
+	Short: "Move a DNS record to new values lowering its TTL first",
+	Long: `
+Changes the values of a record in stages, so clients stop using the old
+values as soon as possible:
+
+  1. The TTL of the record is lowered to --ttl.
+  2. Once the change is applied, it waits for the original TTL to expire.
+  3. The record is changed to the new values.
+  4. The new values are verified.
+  5. The original TTL is restored.
+
+The progress is saved in a state file after every stage. If the cutover is
+interrupted, running the same command again, with or without the
+destinations, resumes it where it stopped. Other destinations are refused
+until the cutover completes or its state file is removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(zoneName) <= 0 {
+			log.Fatal("No zone name specified")
+		}
+		if len(name) <= 0 {
+			log.Fatal("No record name specified")
+		}
+		if len(typ) <= 0 {
+			log.Fatal("No record type specified")
+		}
+		if stateFile == "" {
+			stateFile = cutoverStatePath(zoneName, name, typ)
+		}
+		p := getProvider(zoneName)
+		c, err := got.LoadCutover(stateFile)
+		switch {
+		case err == nil:
+			if len(args) > 0 && !c.HasValues(args) {
+				log.Fatalf(
+					"A cutover of %s %s to %s is in progress in %s. Run without destinations to resume it",
+					c.Name,
+					c.Type,
+					strings.Join(c.Values, ", "),
+					stateFile,
+				)
+			}
+			log.Printf(
+				"Resuming cutover of %s %s to %s at step %s",
+				c.Name,
+				c.Type,
+				strings.Join(c.Values, ", "),
+				c.Step,
+			)
+		case os.IsNotExist(err):
+			if len(args) <= 0 {
+				log.Fatal("No destination specified")
+			}
+			current, err := p.List()
+			if err != nil {
+				log.Fatal(err)
+			}
+			c, err = got.NewCutover(zoneName, name, typ, args, ttl, current)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.Printf(
+				"Cutting over %s %s from %s to %s",
+				c.Name,
+				c.Type,
+				strings.Join(c.OldValues, ", "),
+				strings.Join(c.Values, ", "),
+			)
+		default:
+			log.Fatal(err)
+		}
+		if dryrun {
+			return
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = c.Run(
+			ctx,
+			&journaledProvider{Provider: p, zone: zoneName},
+			newWaiter(),
+			func(c *got.Cutover) error {
+				log.Printf("Cutover of %s %s at step %s", c.Name, c.Type, c.Step)
+				return c.Save(stateFile)
+			},
+		)
+		if err != nil {
+			log.Fatalf("%s. Run again to resume the cutover", err)
+		}
+		if err = os.Remove(stateFile); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Cutover completed")
+	},
+}
+
+// cutoverStatePath returns the default path of the state file for the
+// cutover of the record with name and type in zone.
+func cutoverStatePath(zone, name, typ string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return filepath.Join(
+		home,
+		".got",
+		"cutover",
+		fmt.Sprintf(
+			"%s-%s-%s.json",
+			strings.TrimSuffix(zone, "."),
+			strings.TrimSuffix(name, "."),
+			strings.ToUpper(typ),
+		),
+	)
+}
+
+func init() {
+	RootCmd.AddCommand(cutoverCmd)
+
+	cutoverCmd.PersistentFlags().BoolVarP(
+		&dryrun,
+		"dryrun",
+		"",
+		false,
+		"Don't really do anything",
+	)
+	cutoverCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	cutoverCmd.PersistentFlags().StringVarP(
+		&name,
+		"name",
+		"",
+		"",
+		"Name of the record to cut over.",
+	)
+	cutoverCmd.PersistentFlags().StringVarP(
+		&typ,
+		"type",
+		"",
+		"",
+		"Type of the record to cut over.",
+	)
+	cutoverCmd.PersistentFlags().Int64VarP(
+		&ttl,
+		"ttl",
+		"",
+		60,
+		"TTL to use while the values change.",
+	)
+	cutoverCmd.PersistentFlags().StringVarP(
+		&stateFile,
+		"state",
+		"",
+		"",
+		"File to keep the progress in. Defaults to ~/.got/cutover/<zone>-<name>-<type>.json.",
+	)
+}
@@ -3,49 +3,100 @@ package cmd
 import (
 	"log"
 
+	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/spf13/cobra"
 
 	"github.com/poka-yoke/spaceflight/pkg/got"
 )
 
+var values []string
+
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
 	Use:   "delete [flags] [record] [record] ...",
 	Short: "Remove DNS records",
-	Long:  ``,
+	Long: `
+Removes the records with the names given and --type, or every record
+selected by --filter, or in the CSV or JSON file given with --input
+instead. The SOA and the NS records at the apex of the zone are never
+selected by --filter. Records in the input with values are only removed
+if those are their current values, and --set-identifier can't be given
+along with --input. Every record is validated before removing any.
+
+With --value, only those values are removed from the records named, which
+are deleted if left without values. The changes are shown and confirmed
+before being applied, unless --yes is given.
+
+With --ptr, the PTR records of the addresses removed from A and AAAA
+records stop pointing at them, in the reverse zones of the account.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		svc := connect()
 		if len(zoneName) <= 0 {
 			log.Fatal("No zone name specified")
 		}
-		if len(typ) <= 0 {
-			log.Fatal("No record type specified")
-		}
-		if len(args) <= 0 {
-			log.Fatal("No record names specified")
+		if inputFile != "" {
+			rejectRoutingFlags(cmd)
 		}
-		zoneid, err := got.GetZoneID(zoneName, svc)
+		p := getProvider(zoneName)
+		list, err := p.List()
 		if err != nil {
 			log.Fatal(err)
 		}
-		list, err := got.GetResourceRecordSet(zoneid, svc)
+		var changes []*route53.Change
+		switch {
+		case filterExpression != "":
+			if len(args) > 0 || len(typ) > 0 || inputFile != "" || len(values) > 0 {
+				log.Fatal("Records can't be specified along with --filter")
+			}
+			changes = got.DeleteRecordSetsChangeList(filterRecordSets(
+				got.RemoveZoneAuthority(list, zoneName),
+				filterExpression,
+			))
+			if len(changes) == 0 {
+				log.Fatal("No records match the filter")
+			}
+		case inputFile != "":
+			if len(args) > 0 || len(typ) > 0 || len(values) > 0 {
+				log.Fatal("Records can't be specified along with --input")
+			}
+			changes = bulkChanges(
+				got.BulkDeleteChangeList(readInput(), zoneName, list),
+			)
+		case len(typ) <= 0:
+			log.Fatal("No record type specified")
+		case len(args) <= 0:
+			log.Fatal("No record names specified")
+		case len(values) > 0:
+			changes, err = got.RemoveValuesChangeList(
+				args,
+				typ,
+				setIdentifier,
+				values,
+				list,
+			)
+		default:
+			changes, err = got.DeleteChangeList(args, typ, setIdentifier, list)
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
-		changes := got.DeleteChangeList(args, typ, list)
-		for _, change := range changes {
-			log.Printf(
-				"Change %s added for deletion",
-				*change.ResourceRecordSet.Name,
-			)
+		ptr := ptrChanges(list, changes)
+		if dryrun {
+			previewChanges(list, changes)
+			previewPTRChanges(ptr)
+			return
 		}
-		if !dryrun {
-			res, err := got.ApplyChanges(changes, &zoneid, svc)
-			if err != nil {
-				log.Fatal(err.Error())
-			}
-			log.Println(res)
+		confirmChanges(list, changes, ptr)
+		var ids []string
+		if inputFile != "" {
+			ids = applyBulkChanges(p, zoneName, changes)
+		} else {
+			logChanges(changes)
+			ids = applyChanges(p, zoneName, changes)
+		}
+		if wait {
+			waitForChanges(p, ids)
 		}
+		applyPTRChanges(ptr)
 	},
 }
 
@@ -64,13 +115,8 @@ func init() {
 		false,
 		"Don't really do anything",
 	)
-	deleteCmd.PersistentFlags().BoolVarP(
-		&wait,
-		"wait",
-		"",
-		false,
-		"Don't return until operation is completed",
-	)
+	addWaitFlags(deleteCmd)
+	addPTRFlag(deleteCmd)
 	deleteCmd.PersistentFlags().StringVarP(
 		&zoneName,
 		"zone",
@@ -85,6 +131,18 @@ func init() {
 		"",
 		"Type of the record to upsert.",
 	)
+	addSetIdentifierFlag(deleteCmd)
+	addFilterFlag(deleteCmd)
+	addDiffFlags(deleteCmd)
+	addInputFlags(deleteCmd)
+	addYesFlag(deleteCmd)
+	deleteCmd.PersistentFlags().StringSliceVarP(
+		&values,
+		"value",
+		"",
+		nil,
+		"Value to remove from the records, keeping the rest.",
+	)
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var snapshotFile string
+
+// driftCmd represents the drift command
+var driftCmd = &cobra.Command{
+	Use:   "drift [flags]",
+	Short: "Compare a DNS zone with a snapshot",
+	Long: `
+Compares the records of a zone with a snapshot written by the snapshot
+command, and prints those added, removed or changed since. Exits with an
+error if there is any difference.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(snapshotFile) <= 0 {
+			log.Fatal("No snapshot file specified")
+		}
+		file, err := os.Open(snapshotFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		snapshot, err := got.LoadSnapshot(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		list, err := getProvider(snapshot.Zone).List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		drifts := snapshot.Drift(list)
+		if len(drifts) == 0 {
+			log.Println("Zone matches the snapshot")
+			return
+		}
+		err = got.WriteDrift(os.Stdout, drifts, !noColor && isTerminal(os.Stdout))
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(1)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(driftCmd)
+
+	driftCmd.PersistentFlags().StringVarP(
+		&snapshotFile,
+		"file",
+		"f",
+		"",
+		"YAML snapshot of the zone.",
+	)
+	driftCmd.PersistentFlags().BoolVarP(
+		&noColor,
+		"no-color",
+		"",
+		false,
+		"Don't colorize the differences.",
+	)
+}
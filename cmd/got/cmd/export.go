@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var outputFile string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [flags]",
+	Short: "Export a DNS zone as a BIND zone file",
+	Long: `
+Writes every record in the zone as an RFC 1035 master file, suitable for
+backups or to be loaded by other DNS tools. Alias records can't be
+represented in a master file, so they are written as comments. Only part
+of the zone can be exported with --filter, see the ttl command for its
+syntax.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(zoneName) <= 0 {
+			log.Fatal("No zone name specified")
+		}
+		list, err := getProvider(zoneName).List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		list = filterRecordSets(list, filterExpression)
+		out := os.Stdout
+		if outputFile != "" {
+			out, err = os.Create(outputFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer out.Close()
+		}
+		if err = got.WriteZoneFile(out, zoneName, list); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+
+	exportCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	exportCmd.PersistentFlags().StringVarP(
+		&outputFile,
+		"output",
+		"o",
+		"",
+		"File to write the zone to. Defaults to standard output.",
+	)
+	addFilterFlag(exportCmd)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// healthCheckCmd represents the healthcheck super command
+var healthCheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Manage Route53 health checks",
+	Long: `
+Creates, lists and deletes the health checks of the account. Health checks
+are bound to failover or weighted records by passing their ID or name to
+upsert with --health-check-id.`,
+}
+
+func init() {
+	RootCmd.AddCommand(healthCheckCmd)
+}
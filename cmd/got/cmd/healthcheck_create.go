@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var healthCheck got.HealthCheckSpec
+
+// healthCheckCreateCmd represents the healthcheck create command
+var healthCheckCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a health check",
+	Long: `
+Creates an HTTP, HTTPS or TCP health check of the endpoint at --ip or
+--fqdn, and prints its ID. HTTP and HTTPS checks with --search only pass if
+the response body contains the string.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		check, err := got.CreateHealthCheck(&healthCheck, connect())
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(*check.Id)
+	},
+}
+
+func init() {
+	healthCheckCmd.AddCommand(healthCheckCreateCmd)
+
+	healthCheckCreateCmd.PersistentFlags().StringVarP(
+		&healthCheck.Name,
+		"name",
+		"",
+		"",
+		"Name of the health check.",
+	)
+	healthCheckCreateCmd.PersistentFlags().StringVarP(
+		&healthCheck.Type,
+		"type",
+		"",
+		"HTTP",
+		"Protocol to check: HTTP, HTTPS or TCP.",
+	)
+	healthCheckCreateCmd.PersistentFlags().StringVarP(
+		&healthCheck.IPAddress,
+		"ip",
+		"",
+		"",
+		"IP address of the endpoint.",
+	)
+	healthCheckCreateCmd.PersistentFlags().StringVarP(
+		&healthCheck.FQDN,
+		"fqdn",
+		"",
+		"",
+		"Domain name of the endpoint, also sent as Host header.",
+	)
+	healthCheckCreateCmd.PersistentFlags().Int64VarP(
+		&healthCheck.Port,
+		"port",
+		"",
+		0,
+		"Port of the endpoint. Defaults to 80 for HTTP and 443 for HTTPS.",
+	)
+	healthCheckCreateCmd.PersistentFlags().StringVarP(
+		&healthCheck.ResourcePath,
+		"path",
+		"",
+		"",
+		"Path requested to HTTP and HTTPS endpoints.",
+	)
+	healthCheckCreateCmd.PersistentFlags().StringVarP(
+		&healthCheck.SearchString,
+		"search",
+		"",
+		"",
+		"String the response body must contain.",
+	)
+	healthCheckCreateCmd.PersistentFlags().Int64VarP(
+		&healthCheck.RequestInterval,
+		"interval",
+		"",
+		30,
+		"Seconds between checks, 10 or 30.",
+	)
+	healthCheckCreateCmd.PersistentFlags().Int64VarP(
+		&healthCheck.FailureThreshold,
+		"threshold",
+		"",
+		3,
+		"Consecutive checks failing or passing to change the status.",
+	)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// healthCheckDeleteCmd represents the healthcheck delete command
+var healthCheckDeleteCmd = &cobra.Command{
+	Use:   "delete [flags] <id or name> ...",
+	Short: "Delete health checks",
+	Long: `
+Deletes the health checks with the IDs or names given. Health checks still
+bound to records can't be deleted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) <= 0 {
+			log.Fatal("No health checks specified")
+		}
+		svc := connect()
+		for _, arg := range args {
+			check, err := got.FindHealthCheck(arg, svc)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if dryrun {
+				log.Printf("Health check %s would be deleted", *check.Id)
+				continue
+			}
+			if err = got.DeleteHealthCheck(*check.Id, svc); err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("Health check %s deleted", *check.Id)
+		}
+	},
+}
+
+func init() {
+	healthCheckCmd.AddCommand(healthCheckDeleteCmd)
+
+	healthCheckDeleteCmd.PersistentFlags().BoolVarP(
+		&dryrun,
+		"dryrun",
+		"",
+		false,
+		"Don't really do anything",
+	)
+}
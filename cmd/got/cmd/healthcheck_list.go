@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// healthCheckListCmd represents the healthcheck list command
+var healthCheckListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List health checks",
+	Long:  `Lists every health check in the account, along with its name.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		svc := connect()
+		checks, err := got.ListHealthChecks(svc)
+		if err != nil {
+			log.Fatal(err)
+		}
+		names, err := got.HealthCheckNames(checks, svc)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = got.WriteHealthChecks(os.Stdout, checks, names); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	healthCheckCmd.AddCommand(healthCheckListCmd)
+}
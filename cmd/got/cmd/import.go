@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var keep bool
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import [flags] <zonefile>",
+	Short: "Import a BIND zone file into a DNS zone",
+	Long: `
+Reads an RFC 1035 master file and applies the changes needed for the
+zone to match it. Records missing from the file are deleted unless
+--keep is specified. The SOA and the NS records at the apex of the zone
+are never modified, nor are alias records, as these can't be represented
+in a master file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(zoneName) <= 0 {
+			log.Fatal("No zone name specified")
+		}
+		if len(args) != 1 {
+			log.Fatal("No zone file specified")
+		}
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		desired, err := got.ParseZoneFile(file, zoneName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p := getProvider(zoneName)
+		current, err := p.List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		current = got.FilterResourceRecords(
+			current,
+			[]string{""},
+			func(
+				elem *route53.ResourceRecordSet,
+				filter string,
+			) *route53.ResourceRecordSet {
+				if elem.AliasTarget == nil {
+					return elem
+				}
+				return nil
+			},
+		)
+		changes := got.DiffRecordSets(
+			got.RemoveZoneAuthority(current, zoneName),
+			got.RemoveZoneAuthority(desired, zoneName),
+			!keep,
+		)
+		if len(changes) == 0 {
+			log.Println("Zone is already up to date")
+			return
+		}
+		logChanges(changes)
+		if !dryrun {
+			ids := applyChanges(p, zoneName, changes)
+			if wait {
+				waitForChanges(p, ids)
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+
+	importCmd.PersistentFlags().BoolVarP(
+		&dryrun,
+		"dryrun",
+		"",
+		false,
+		"Don't really do anything",
+	)
+	importCmd.PersistentFlags().BoolVarP(
+		&keep,
+		"keep",
+		"",
+		false,
+		"Don't delete records missing from the zone file",
+	)
+	importCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	addWaitFlags(importCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var zoneFile, failOn string
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint [flags]",
+	Short: "Check the records of a DNS zone for common mistakes",
+	Long: `
+Performs static checks over the records of a zone, or of a BIND zone file
+if --file is specified:
+
+  cname-apex       CNAME at the zone apex
+  cname-conflict   CNAME next to other types on the same name
+  cname-dangling   CNAME pointing to a name in the zone without records
+  duplicate-value  Values repeated in a record
+  ttl-outlier      TTL far lower or higher than the zone median
+  target-cname     MX or SRV target pointing to a CNAME
+
+Every hosted zone in the account is checked with --all-zones.
+
+Exits with an error if any finding is at least as serious as --fail-on,
+so it can be used to gate a pipeline.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		threshold, err := got.ParseSeverity(failOn)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var findings []*got.Finding
+		if zoneFile != "" {
+			if len(zoneName) <= 0 || allZones {
+				log.Fatal("A single zone name must be specified with --file")
+			}
+			file, err := os.Open(zoneFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer file.Close()
+			records, err := got.ParseZoneFile(file, zoneName)
+			if err != nil {
+				log.Fatal(err)
+			}
+			findings = got.Lint(records, zoneName)
+		} else {
+			for _, zone := range selectedZones() {
+				records, err := zone.provider.List()
+				if err != nil {
+					log.Fatal(err)
+				}
+				findings = append(findings, got.Lint(records, zone.name)...)
+			}
+		}
+		if err = got.WriteFindings(os.Stdout, findings); err != nil {
+			log.Fatal(err)
+		}
+		if severity, found := got.HighestSeverity(findings); found &&
+			severity >= threshold {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(lintCmd)
+
+	lintCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	lintCmd.PersistentFlags().StringVarP(
+		&zoneFile,
+		"file",
+		"f",
+		"",
+		"BIND zone file to check instead of the records in the zone.",
+	)
+	lintCmd.PersistentFlags().StringVarP(
+		&failOn,
+		"fail-on",
+		"",
+		"warning",
+		"Lowest severity of the findings making lint fail: info, warning or error.",
+	)
+	addAllZonesFlag(lintCmd)
+}
@@ -1,38 +1,123 @@
 package cmd
 
 import (
-	"fmt"
+	"log"
+	"os"
 
+	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
 )
 
+var listNames, listTypes, listTTLs, listValues []string
+var format string
+
 // listCmd represents the list command
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "list [flags]",
+	Short: "List the records of a DNS zone",
+	Long: `
+Lists the records of a zone sorted by name and type. Records can be
+filtered by name, which may be a shell pattern matched label by label,
+type, TTL, with an optional comparison operator, and value. Records
+matching any of the values of a filter pass it, and must pass every
+filter specified. Records of every hosted zone in the account are listed
+with --all-zones.
+
+More complex selections can be made with --filter, an expression such as
+'name = staging-*.example.com and type = CNAME'. See the ttl command for
+its syntax.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// TODO: Work your own magic here
-		fmt.Println("list called")
+		for _, filter := range listTTLs {
+			if err := got.ValidateTTLFilter(filter); err != nil {
+				log.Fatal(err)
+			}
+		}
+		var list []*route53.ResourceRecordSet
+		for _, zone := range selectedZones() {
+			records, err := zone.provider.List()
+			if err != nil {
+				log.Fatal(err)
+			}
+			list = append(list, records...)
+		}
+		if len(listNames) > 0 {
+			list = got.FilterResourceRecords(list, listNames, got.NameFilter)
+		}
+		if len(listTypes) > 0 {
+			list = got.FilterResourceRecords(list, listTypes, got.TypeFilter)
+		}
+		if len(listTTLs) > 0 {
+			list = got.FilterResourceRecords(list, listTTLs, got.TTLFilter)
+		}
+		if len(listValues) > 0 {
+			list = got.FilterResourceRecords(list, listValues, got.ValueFilter)
+		}
+		list = filterRecordSets(list, filterExpression)
+		got.SortResourceRecordSets(list)
+		var err error
+		switch format {
+		case "table":
+			err = got.WriteTable(os.Stdout, list)
+		case "json":
+			err = got.WriteJSON(os.Stdout, list)
+		case "csv":
+			err = got.WriteCSV(os.Stdout, list)
+		default:
+			log.Fatalf("Unknown output format %s", format)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(listCmd)
 
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// listCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// listCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-
+	listCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	listCmd.PersistentFlags().StringSliceVarP(
+		&listNames,
+		"name",
+		"n",
+		[]string{},
+		"Only list records with this name or matching this pattern.",
+	)
+	listCmd.PersistentFlags().StringSliceVarP(
+		&listTypes,
+		"type",
+		"t",
+		[]string{},
+		"Only list records of this type.",
+	)
+	listCmd.PersistentFlags().StringSliceVarP(
+		&listTTLs,
+		"ttl",
+		"",
+		[]string{},
+		"Only list records with this TTL, e.g. 300 or '>60'.",
+	)
+	listCmd.PersistentFlags().StringSliceVarP(
+		&listValues,
+		"value",
+		"",
+		[]string{},
+		"Only list records with a value containing this.",
+	)
+	listCmd.PersistentFlags().StringVarP(
+		&format,
+		"format",
+		"",
+		"table",
+		"Output format: table, json or csv.",
+	)
+	addFilterFlag(listCmd)
+	addAllZonesFlag(listCmd)
 }
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var specFile string
+var prune bool
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [flags]",
+	Short: "Show the changes needed for a DNS zone to match a spec",
+	Long: `
+Reads a YAML spec describing the desired records of a zone, and prints
+the changes that apply would perform for the zone to match it. Records
+not present in the spec are only deleted when pruning, either with
+--prune or with "prune: true" in the spec. Alias records and records with
+routing policies can't be described in the spec, so they're never pruned.
+
+Fails listing the records of the spec that Route53 would reject, those
+with the name and type of alias records or records with routing policies,
+and CNAME records sharing their name with records of other types left in
+the zone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _, changes := planChanges()
+		if len(changes) == 0 {
+			log.Println("Zone is already up to date")
+			return
+		}
+		if err := got.WriteChanges(os.Stdout, changes); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// planChanges loads the spec and returns it, along with the Provider of the
+// zone and the changes needed for it to match the spec.
+func planChanges() (
+	spec *got.Spec,
+	p got.Provider,
+	changes []*route53.Change,
+) {
+	if len(specFile) <= 0 {
+		log.Fatal("No spec file specified")
+	}
+	file, err := os.Open(specFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	spec, err = got.LoadSpec(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p = getProvider(spec.Zone)
+	current, err := p.List()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if errs := spec.Conflicts(current, prune); len(errs) > 0 {
+		for _, err := range errs {
+			log.Println(err)
+		}
+		log.Fatalf("%d conflicting records, the spec can't be applied", len(errs))
+	}
+	changes = spec.Changes(current, prune)
+	return
+}
+
+func init() {
+	RootCmd.AddCommand(planCmd)
+
+	planCmd.PersistentFlags().StringVarP(
+		&specFile,
+		"file",
+		"f",
+		"",
+		"YAML spec of the zone.",
+	)
+	planCmd.PersistentFlags().BoolVarP(
+		&prune,
+		"prune",
+		"",
+		false,
+		"Delete records not present in the spec",
+	)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// ptrAuditCmd represents the ptr-audit command
+var ptrAuditCmd = &cobra.Command{
+	Use:   "ptr-audit [flags]",
+	Short: "Check reverse DNS matches the records of a zone",
+	Long: `
+Checks the PTR records in the reverse zones of the account, in-addr.arpa
+and ip6.arpa subdomains, against the records of the zone:
+
+  ptr-missing    A or AAAA record whose address has no PTR record
+  ptr-dangling   PTR record pointing to a name in the zone without records
+
+Every hosted zone in the account is checked with --all-zones. Exits with
+an error if anything is found. Only the route53 provider is supported.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("provider") != "route53" {
+			log.Fatal("ptr-audit is only supported by the route53 provider")
+		}
+		var names []string
+		var records []*route53.ResourceRecordSet
+		for _, zone := range selectedZones() {
+			list, err := zone.provider.List()
+			if err != nil {
+				log.Fatal(err)
+			}
+			names = append(names, zone.name)
+			records = append(records, list...)
+		}
+		reverse, err := got.NewReverseZones(zoneSelector(), connect())
+		if err != nil {
+			log.Fatal(err)
+		}
+		findings, err := reverse.Audit(names, records)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = got.WriteFindings(os.Stdout, findings); err != nil {
+			log.Fatal(err)
+		}
+		if len(findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(ptrAuditCmd)
+
+	ptrAuditCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	addAllZonesFlag(ptrAuditCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [flags] <change-id>",
+	Short: "Undo a change recorded in the journal",
+	Long: `
+Every change applied by got is recorded in a journal, along with the
+previous state of the records it touched. Rollback restores those
+records to that state, applying the inverse of the change identified by
+the argument, in the same hosted zone it was applied to. The rollback is
+a change itself, so it's recorded in the journal as well.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			log.Fatal("No change ID specified")
+		}
+		entry, err := got.NewJournal(journalPath()).Find(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf(
+			"Rolling back change %s to %s by %s at %s",
+			entry.ID,
+			entry.Zone,
+			entry.User,
+			entry.Time,
+		)
+		var p got.Provider
+		if entry.ZoneID != "" && viper.GetString("provider") == "route53" {
+			p = got.NewRoute53ProviderByID(entry.ZoneID, connect())
+		} else {
+			p = getProvider(entry.Zone)
+		}
+		current, err := p.List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		changes := entry.RollbackChanges(current)
+		if len(changes) == 0 {
+			log.Println("Records are already in their previous state")
+			return
+		}
+		logChanges(changes)
+		if !dryrun {
+			ids := applyChanges(p, entry.Zone, changes)
+			if wait {
+				waitForChanges(p, ids)
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.PersistentFlags().BoolVarP(
+		&dryrun,
+		"dryrun",
+		"",
+		false,
+		"Don't really do anything",
+	)
+	addWaitFlags(rollbackCmd)
+}
@@ -42,6 +42,28 @@ func init() {
 	// will be global for your application.
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.got.yaml)")
+	RootCmd.PersistentFlags().String("journal", "", "journal of changes (default is $HOME/.got/journal.jsonl)")
+	RootCmd.PersistentFlags().String("provider", "route53", "DNS provider: route53 or rfc2136")
+	RootCmd.PersistentFlags().String("server", "", "DNS server as host:port, for the rfc2136 provider")
+	RootCmd.PersistentFlags().String("tsig-name", "", "TSIG key name, for the rfc2136 provider")
+	RootCmd.PersistentFlags().String("tsig-secret", "", "TSIG key secret in base64, for the rfc2136 provider")
+	RootCmd.PersistentFlags().String("tsig-algorithm", "", "TSIG algorithm, for the rfc2136 provider (default hmac-sha256)")
+	RootCmd.PersistentFlags().Bool("private", false, "select only private (or, if false, public) hosted zones")
+	RootCmd.PersistentFlags().String("vpc", "", "select only private hosted zones associated with this VPC ID")
+	for _, flag := range []string{
+		"journal",
+		"provider",
+		"server",
+		"tsig-name",
+		"tsig-secret",
+		"tsig-algorithm",
+		"private",
+		"vpc",
+	} {
+		if err := viper.BindPFlag(flag, RootCmd.PersistentFlags().Lookup(flag)); err != nil {
+			panic(err)
+		}
+	}
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	RootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
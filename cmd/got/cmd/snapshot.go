@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [flags]",
+	Short: "Write a YAML snapshot of a DNS zone",
+	Long: `
+Writes every record in the zone, other than the SOA and the NS records at
+the apex, as YAML sorted by name, type and set identifier. Snapshots of
+the same records are always identical, so they can be committed and
+compared later on with the drift command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(zoneName) <= 0 {
+			log.Fatal("No zone name specified")
+		}
+		list, err := getProvider(zoneName).List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		out := os.Stdout
+		if outputFile != "" {
+			out, err = os.Create(outputFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer out.Close()
+		}
+		if err = got.NewSnapshot(zoneName, list).Write(out); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(snapshotCmd)
+
+	snapshotCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	snapshotCmd.PersistentFlags().StringVarP(
+		&outputFile,
+		"output",
+		"o",
+		"",
+		"File to write the snapshot to. Defaults to standard output.",
+	)
+}
@@ -17,96 +17,87 @@ var ttl int64
 var ttlCmd = &cobra.Command{
 	Use:   "ttl [flags] [filters ...]",
 	Short: "Modify Time To Live of a set of records in a DNS zone",
-	Long:  ``,
+	Long: `
+Changes the TTL of the records of a zone selected by --filter, an
+expression such as:
+
+  type in (A,CNAME) and name =~ "^api-" and ttl > 300
+
+Fields are name, type, ttl, value and set_identifier. They are compared
+with =, !=, =~ and !~ for regular expressions, in and not in for lists,
+and <, <=, > and >= for TTLs, and combined with and, or, not and
+parentheses.
+
+The records can also be selected by passing their names, with --name, or
+types, with --type, as arguments, or every other record with --exclude.
+Names must match exactly, patterns such as *.example.com are only
+supported by --filter, e.g. name in ("*.example.com").
+
+With --all-zones, records are changed in every hosted zone in the
+account.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		svc := connect()
-		if len(zoneName) <= 0 {
-			log.Fatal("No zone name specified")
-		}
-		zoneID, err := got.GetZoneID(zoneName, svc)
-		if err != nil {
-			log.Fatal(err)
+		for _, zone := range selectedZones() {
+			list, err := zone.provider.List()
+			if err != nil {
+				log.Fatal(err)
+			}
+			list = filterRecordSets(ttlRecordSets(list, args), filterExpression)
+			if setIdentifier != "" {
+				list = got.FilterResourceRecords(
+					list,
+					[]string{setIdentifier},
+					got.SetIdentifierFilter,
+				)
+			}
+			changes := got.TTLChangeList(list, ttl)
+			if allZones && len(changes) == 0 {
+				continue
+			}
+			if dryrun {
+				previewChanges(list, changes)
+				continue
+			}
+			ids := applyChanges(zone.provider, zone.name, changes)
+			if wait {
+				waitForChanges(zone.provider, ids)
+			}
 		}
+	},
+}
 
-		list, err := got.GetResourceRecordSet(zoneID, svc)
-		if err != nil {
-			log.Fatal(err)
-		}
-		// Filter list in between
-		if exclude && filterByType {
-			list = got.FilterResourceRecords(
-				list,
-				args,
-				func(
-					elem *route53.ResourceRecordSet,
-					filter string,
-				) *route53.ResourceRecordSet {
-					if *elem.Type != filter {
-						return elem
-					}
-					return nil
-				},
-			)
-		} else if exclude && filterByName {
-			list = got.FilterResourceRecords(
-				list,
-				args,
-				func(
-					elem *route53.ResourceRecordSet,
-					filter string,
-				) *route53.ResourceRecordSet {
-					if *elem.Name != filter {
-						return elem
-					}
-					return nil
-				},
-			)
-		} else if filterByType {
-			list = got.FilterResourceRecords(
-				list,
-				args,
-				func(
-					elem *route53.ResourceRecordSet,
-					filter string,
-				) *route53.ResourceRecordSet {
-					if *elem.Type == filter {
-						return elem
-					}
-					return nil
-				},
-			)
-		} else if filterByName {
-			list = got.FilterResourceRecords(
-				list,
-				args,
-				func(
-					elem *route53.ResourceRecordSet,
-					filter string,
-				) *route53.ResourceRecordSet {
-					if *elem.Name == filter {
-						return elem
-					}
-					return nil
-				},
-			)
+// ttlRecordSets returns the record sets in list with the names or types in
+// args, or every other one if excluding them. Names are matched exactly,
+// patterns are only supported by --filter.
+func ttlRecordSets(
+	list []*route53.ResourceRecordSet,
+	args []string,
+) []*route53.ResourceRecordSet {
+	var filter func(*route53.ResourceRecordSet, string) *route53.ResourceRecordSet
+	switch {
+	case filterByType:
+		filter = got.TypeFilter
+	case filterByName:
+		filter = got.ExactNameFilter
+	default:
+		return list
+	}
+	if len(args) <= 0 {
+		log.Fatal("No filters specified")
+	}
+	if !exclude {
+		return got.FilterResourceRecords(list, args, filter)
+	}
+	result := []*route53.ResourceRecordSet{}
+	for _, rrs := range list {
+		if len(got.FilterResourceRecords(
+			[]*route53.ResourceRecordSet{rrs},
+			args,
+			filter,
+		)) == 0 {
+			result = append(result, rrs)
 		}
-		changeResponse, err := got.UpsertResourceRecordSetTTL(
-			list,
-			ttl,
-			&zoneID,
-			svc,
-		)
-		if err != nil {
-			log.Panic(err.Error())
-		}
-		if wait && !dryrun {
-			got.WaitForChangeToComplete(
-				changeResponse.ChangeInfo,
-				svc,
-			)
-			log.Println("All changes applied")
-		}
-	},
+	}
+	return result
 }
 
 func init() {
@@ -131,13 +122,7 @@ func init() {
 		false,
 		"Exclude records matching list",
 	)
-	ttlCmd.PersistentFlags().BoolVarP(
-		&wait,
-		"wait",
-		"",
-		false,
-		"Don't return until operation is completed",
-	)
+	addWaitFlags(ttlCmd)
 	ttlCmd.PersistentFlags().StringVarP(
 		&zoneName,
 		"zone",
@@ -166,6 +151,10 @@ func init() {
 		false,
 		"Filters are to be used as types",
 	)
+	addSetIdentifierFlag(ttlCmd)
+	addFilterFlag(ttlCmd)
+	addAllZonesFlag(ttlCmd)
+	addDiffFlags(ttlCmd)
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -3,6 +3,7 @@ package cmd
 import (
 	"log"
 
+	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/spf13/cobra"
 
 	"github.com/poka-yoke/spaceflight/pkg/got"
@@ -12,43 +13,78 @@ var name, typ string
 
 // upsertCmd represents the upsert command
 var upsertCmd = &cobra.Command{
-	Use:   "upsert [flags] <destination>",
+	Use:   "upsert [flags] [destination] ...",
 	Short: "Upsert a DNS record",
-	Long:  ``,
+	Long: `
+Upserts the record with --name and --type, with the destinations given as
+values, or every record in the CSV or JSON file given with --input
+instead. CSV files have a header with the name, type, ttl and value
+columns, and a row per value, as written by list --format csv. Records
+without TTL get --ttl. Alias records and routing policies aren't
+supported with --input, so neither are their flags. Every record is
+validated before changing any.
+
+With --ptr, the PTR records of the addresses of A and AAAA records point
+at them too, in the reverse zones of the account, found by name. Those of
+the addresses no longer in the records stop pointing at them.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		svc := connect()
 		if len(zoneName) <= 0 {
 			log.Fatal("No zone name specified")
 		}
-		if len(name) <= 0 {
-			log.Fatal("No record name specified")
-		}
-		if len(typ) <= 0 {
-			log.Fatal("No record type specified")
+		var changes []*route53.Change
+		if inputFile != "" {
+			if len(name) > 0 || len(typ) > 0 || len(args) > 0 {
+				log.Fatal("Records can't be specified along with --input")
+			}
+			rejectRoutingFlags(cmd)
+			changes = bulkChanges(
+				got.BulkUpsertChangeList(readInput(), zoneName, ttl),
+			)
+		} else {
+			if len(name) <= 0 {
+				log.Fatal("No record name specified")
+			}
+			if len(typ) <= 0 {
+				log.Fatal("No record type specified")
+			}
+			if len(args) <= 0 && len(aliasTarget) <= 0 {
+				log.Fatal("No destination specified")
+			}
+			if len(args) > 0 && len(aliasTarget) > 0 {
+				log.Fatal("Destinations can't be specified along with --alias-target")
+			}
+			resolveHealthCheck()
+			list := got.NewResourceRecordList(args)
+			changes = got.UpsertChangeList(list, ttl, name, typ, routing())
 		}
-		if len(args) <= 0 {
-			log.Fatal("No destination specified")
+		p := getProvider(zoneName)
+		var current []*route53.ResourceRecordSet
+		if dryrun || syncPTR {
+			var err error
+			if current, err = p.List(); err != nil {
+				log.Fatal(err)
+			}
 		}
-		zoneid, err := got.GetZoneID(zoneName, svc)
-		if err != nil {
-			log.Fatal(err)
+		ptr := ptrChanges(current, changes)
+		if dryrun {
+			previewChanges(current, changes)
+			previewPTRChanges(ptr)
+			return
 		}
-		list := got.NewResourceRecordList(args)
-		changes := got.UpsertChangeList(list, ttl, name, typ)
-		for _, change := range changes {
-			log.Printf(
-				"Change %s added with TTL %v",
-				*change.ResourceRecordSet.Name,
-				ttl,
-			)
+		var ids []string
+		if inputFile != "" {
+			ids = applyBulkChanges(p, zoneName, changes)
+		} else {
+			logChanges(changes)
+			ids = applyChanges(p, zoneName, changes)
 		}
-		if !dryrun {
-			res, err := got.ApplyChanges(changes, &zoneid, svc)
-			if err != nil {
-				log.Fatal(err.Error())
-			}
-			log.Println(res)
+		switch {
+		case verify:
+			verifyChanges(p, zoneName, ids, changes)
+		case wait:
+			waitForChanges(p, ids)
 		}
+		applyPTRChanges(ptr)
 	},
 }
 
@@ -74,13 +110,8 @@ func init() {
 		false,
 		"Exclude records matching list",
 	)
-	upsertCmd.PersistentFlags().BoolVarP(
-		&wait,
-		"wait",
-		"",
-		false,
-		"Don't return until operation is completed",
-	)
+	addWaitFlags(upsertCmd)
+	addPTRFlag(upsertCmd)
 	upsertCmd.PersistentFlags().StringVarP(
 		&zoneName,
 		"zone",
@@ -109,6 +140,10 @@ func init() {
 		"",
 		"Type of the record to upsert.",
 	)
+	addRoutingFlags(upsertCmd)
+	addVerifyFlags(upsertCmd)
+	addDiffFlags(upsertCmd)
+	addInputFlags(upsertCmd)
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [flags]",
+	Short: "Check DNS servers answer with the records in the zone",
+	Long: `
+Queries every name server the parent zone delegates the zone to directly,
+and any public resolver given, for the records in the zone, reporting per
+server whether the answer matches. The delegation is looked up through the
+first resolver given, or else the first of the system. A change being
+INSYNC only means the DNS service applied it, while stale delegations or
+caches may still answer with old values.
+
+Records can be narrowed down with --name and --type. Aliases and records
+with routing policies are skipped, as their answers depend on the target
+or the client. Exits with an error if any answer doesn't match.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(zoneName) <= 0 {
+			log.Fatal("No zone name specified")
+		}
+		p := getProvider(zoneName)
+		list, err := p.List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if name != "" {
+			list = got.FilterResourceRecords(list, []string{name}, got.NameFilter)
+		}
+		if typ != "" {
+			list = got.FilterResourceRecords(list, []string{typ}, got.TypeFilter)
+		}
+		if len(list) == 0 {
+			log.Fatal("No records to verify")
+		}
+		if !verifyRecordSets(zoneName, list) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	verifyCmd.PersistentFlags().StringVarP(
+		&name,
+		"name",
+		"",
+		"",
+		"Name of the records to verify. Accepts glob patterns.",
+	)
+	verifyCmd.PersistentFlags().StringVarP(
+		&typ,
+		"type",
+		"",
+		"",
+		"Type of the records to verify.",
+	)
+	addResolverFlags(verifyCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var zoneID, vpcID, vpcRegion string
+
+// zoneCmd represents the zone super command
+var zoneCmd = &cobra.Command{
+	Use:   "zone",
+	Short: "Manage the VPCs of private hosted zones",
+	Long: `
+Associates VPCs with private hosted zones, disassociates them, and lists
+them. VPCs owned by another account are authorized by the account owning
+the zone with associate --authorize first, and then associated from their
+own account with associate --zone-id. Only the route53 provider is
+supported.`,
+}
+
+func init() {
+	RootCmd.AddCommand(zoneCmd)
+}
+
+// addZoneFlags adds the flags selecting a private hosted zone to the
+// command.
+func addZoneFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&zoneName,
+		"zone",
+		"",
+		"",
+		"Name of the zone to work on.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&zoneID,
+		"zone-id",
+		"",
+		"",
+		"ID of the zone to work on, needed for zones of other accounts.",
+	)
+}
+
+// addVPCFlags adds the flags describing a VPC to the command.
+func addVPCFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&vpcID,
+		"vpc-id",
+		"",
+		"",
+		"ID of the VPC.",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&vpcRegion,
+		"vpc-region",
+		"",
+		"",
+		"Region of the VPC. Defaults to the AWS region configured.",
+	)
+}
+
+// privateZoneID returns the ID given with --zone-id or, failing that, the
+// ID of the private hosted zone named with --zone.
+func privateZoneID(svc *route53.Route53) string {
+	if viper.GetString("provider") != "route53" {
+		log.Fatal("VPCs are only supported by the route53 provider")
+	}
+	if zoneID != "" {
+		if zoneName != "" {
+			log.Fatal("--zone and --zone-id can't be specified together")
+		}
+		return zoneID
+	}
+	if len(zoneName) <= 0 {
+		log.Fatal("No zone name specified")
+	}
+	selector := zoneSelector()
+	selector.Private = aws.Bool(true)
+	hz, err := got.FindHostedZone(zoneName, selector, svc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return *hz.Id
+}
+
+// selectedVPC returns the VPC described by the flags. Its region defaults
+// to the one configured for AWS, with AWS_REGION or in the shared config.
+func selectedVPC() *route53.VPC {
+	if len(vpcID) <= 0 {
+		log.Fatal("No VPC ID specified")
+	}
+	region := vpcRegion
+	if region == "" {
+		region = configuredRegion()
+	}
+	if region == "" {
+		log.Fatal("No VPC region specified")
+	}
+	return got.NewVPC(vpcID, region)
+}
+
+// configuredRegion returns the AWS region configured in the environment or
+// the shared config, if any.
+func configuredRegion() string {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return aws.StringValue(sess.Config.Region)
+}
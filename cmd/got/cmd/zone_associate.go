@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var authorize bool
+
+// zoneAssociateCmd represents the zone associate command
+var zoneAssociateCmd = &cobra.Command{
+	Use:   "associate [flags]",
+	Short: "Associate a VPC with a private hosted zone",
+	Long: `
+Associates the VPC with --vpc-id and --vpc-region with the private hosted
+zone, so it's resolved from within the VPC. With --authorize, the VPC,
+owned by another account, is only authorized to be associated with the
+zone from that account.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		svc := connect()
+		id := privateZoneID(svc)
+		vpc := selectedVPC()
+		if authorize {
+			if dryrun {
+				log.Printf("VPC %s would be authorized for zone %s", vpcID, id)
+				return
+			}
+			if err := got.AuthorizeVPC(id, vpc, svc); err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("VPC %s authorized for zone %s", vpcID, id)
+			return
+		}
+		if dryrun {
+			log.Printf("VPC %s would be associated with zone %s", vpcID, id)
+			return
+		}
+		changeInfo, err := got.AssociateVPC(id, vpc, svc)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("VPC %s associated with zone %s", vpcID, id)
+		logChangeIDs([]string{*changeInfo.Id})
+		if wait {
+			waitForChanges(
+				got.NewRoute53ProviderByID(id, svc),
+				[]string{*changeInfo.Id},
+			)
+		}
+	},
+}
+
+func init() {
+	zoneCmd.AddCommand(zoneAssociateCmd)
+
+	addZoneFlags(zoneAssociateCmd)
+	addVPCFlags(zoneAssociateCmd)
+	addWaitFlags(zoneAssociateCmd)
+	zoneAssociateCmd.PersistentFlags().BoolVarP(
+		&authorize,
+		"authorize",
+		"",
+		false,
+		"Only authorize the VPC, owned by another account, to be associated.",
+	)
+	zoneAssociateCmd.PersistentFlags().BoolVarP(
+		&dryrun,
+		"dryrun",
+		"",
+		false,
+		"Don't really do anything",
+	)
+}
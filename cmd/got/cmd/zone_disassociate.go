@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+var revoke bool
+
+// zoneDisassociateCmd represents the zone disassociate command
+var zoneDisassociateCmd = &cobra.Command{
+	Use:   "disassociate [flags]",
+	Short: "Disassociate a VPC from a private hosted zone",
+	Long: `
+Disassociates the VPC with --vpc-id and --vpc-region from the private
+hosted zone. The last VPC of a zone can't be disassociated. With --revoke,
+the authorization of a VPC owned by another account is revoked instead,
+which doesn't disassociate it if already associated.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		svc := connect()
+		id := privateZoneID(svc)
+		vpc := selectedVPC()
+		if revoke {
+			if dryrun {
+				log.Printf("VPC %s would be revoked for zone %s", vpcID, id)
+				return
+			}
+			if err := got.RevokeVPC(id, vpc, svc); err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("VPC %s revoked for zone %s", vpcID, id)
+			return
+		}
+		if dryrun {
+			log.Printf("VPC %s would be disassociated from zone %s", vpcID, id)
+			return
+		}
+		changeInfo, err := got.DisassociateVPC(id, vpc, svc)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("VPC %s disassociated from zone %s", vpcID, id)
+		logChangeIDs([]string{*changeInfo.Id})
+		if wait {
+			waitForChanges(
+				got.NewRoute53ProviderByID(id, svc),
+				[]string{*changeInfo.Id},
+			)
+		}
+	},
+}
+
+func init() {
+	zoneCmd.AddCommand(zoneDisassociateCmd)
+
+	addZoneFlags(zoneDisassociateCmd)
+	addVPCFlags(zoneDisassociateCmd)
+	addWaitFlags(zoneDisassociateCmd)
+	zoneDisassociateCmd.PersistentFlags().BoolVarP(
+		&revoke,
+		"revoke",
+		"",
+		false,
+		"Only revoke the authorization of the VPC, owned by another account.",
+	)
+	zoneDisassociateCmd.PersistentFlags().BoolVarP(
+		&dryrun,
+		"dryrun",
+		"",
+		false,
+		"Don't really do anything",
+	)
+}
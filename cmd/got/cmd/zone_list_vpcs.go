@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/poka-yoke/spaceflight/pkg/got"
+)
+
+// zoneListVPCsCmd represents the zone list-vpcs command
+var zoneListVPCsCmd = &cobra.Command{
+	Use:   "list-vpcs [flags]",
+	Short: "List the VPCs of a private hosted zone",
+	Long: `
+Lists the VPCs associated with the private hosted zone, followed by those
+owned by other accounts authorized to be associated with it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		svc := connect()
+		associated, authorized, err := got.ZoneVPCs(privateZoneID(svc), svc)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = got.WriteZoneVPCs(os.Stdout, associated, authorized); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	zoneCmd.AddCommand(zoneListVPCsCmd)
+
+	addZoneFlags(zoneListVPCsCmd)
+}
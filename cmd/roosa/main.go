@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -11,11 +12,12 @@ import (
 	"github.com/poka-yoke/spaceflight/pkg/roosa"
 )
 
-var zoneName string
+var zoneName, format string
 
 // Init sets the flag parsing and input validations
 func Init() {
 	flag.StringVar(&zoneName, "zonename", "", "Hosted Zone's name to traverse")
+	flag.StringVar(&format, "format", "text", "Output format: text, json, dot or mermaid")
 
 	flag.Parse()
 
@@ -38,5 +40,24 @@ func main() {
 	referenceTreeList := roosa.NewReferenceTreeList(
 		roosa.GetResourceRecordSet(zoneID, svc),
 	)
-	fmt.Print(referenceTreeList)
+	switch format {
+	case "text":
+		fmt.Print(referenceTreeList)
+	case "json":
+		content, err := json.MarshalIndent(referenceTreeList, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(content))
+	case "dot":
+		dot, err := referenceTreeList.DOT()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(dot)
+	case "mermaid":
+		fmt.Print(referenceTreeList.Mermaid())
+	default:
+		log.Fatalf("Unknown format %s", format)
+	}
 }
@@ -38,15 +38,15 @@ func NewLinearBackoff(ready func() bool, sleep, timelimit time.Duration) *Backof
 	}
 }
 
-// Do executes the request with backoff enabled
+// Do executes the request with backoff enabled. Failed requests are retried
+// after waiting as well.
 func (b Backoff) Do(req *request.Request) {
 	sleep := b.wait
 	for {
 		err := req.Send()
 		if err != nil {
-			continue
-		}
-		if b.ready() {
+			log.Printf("Request failed: %s", err)
+		} else if b.ready() {
 			return
 		}
 		log.Printf("Waiting for %fs\n", sleep.Seconds())
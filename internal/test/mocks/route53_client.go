@@ -0,0 +1,852 @@
+package mocks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+const (
+	// maxRecordSetsPage is the most record sets Route53 returns per page.
+	maxRecordSetsPage = 300
+	// maxBatchRecords is the most ResourceRecord elements Route53 accepts
+	// in a change batch.
+	maxBatchRecords = 1000
+	// maxBatchValueLength is the most characters Route53 accepts among the
+	// values in a change batch.
+	maxBatchValueLength = 32000
+)
+
+// Route53Client mocks Route53API methods for testing of Route53 API
+// clients. It keeps hosted zones and their record sets in memory, and
+// changes them as Route53 would.
+type Route53Client struct {
+	route53iface.Route53API
+	// PageSize is the most items returned per page by the List methods,
+	// if lower than the Route53 limits.
+	PageSize int
+	// PendingPolls is the amount of times GetChange reports a change as
+	// PENDING before reporting it as INSYNC.
+	PendingPolls int
+	zones        []*route53.HostedZone
+	vpcs         map[string][]*route53.VPC
+	authorized   map[string][]*route53.VPC
+	records      map[string][]*route53.ResourceRecordSet
+	changes      map[string]*route53.ChangeInfo
+	polls        map[string]int
+	lastID       int
+}
+
+// NewRoute53Client creates a Route53Client without hosted zones.
+func NewRoute53Client() *Route53Client {
+	return &Route53Client{
+		vpcs:       map[string][]*route53.VPC{},
+		authorized: map[string][]*route53.VPC{},
+		records:    map[string][]*route53.ResourceRecordSet{},
+		changes:    map[string]*route53.ChangeInfo{},
+		polls:      map[string]int{},
+	}
+}
+
+// newID returns a new identifier with prefix.
+func (m *Route53Client) newID(prefix string) string {
+	m.lastID++
+	return fmt.Sprintf("%s%06d", prefix, m.lastID)
+}
+
+// invalidChangeBatch returns the error Route53 returns for change batches
+// it rejects.
+func invalidChangeBatch(format string, a ...interface{}) error {
+	return awserr.New(
+		route53.ErrCodeInvalidChangeBatch,
+		fmt.Sprintf(format, a...),
+		nil,
+	)
+}
+
+// fqdn returns name in the form Route53 keeps it, lower case and with a
+// trailing dot.
+func fqdn(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// reversedName returns name with its labels reversed, as Route53 sorts
+// record sets by it.
+func reversedName(name string) string {
+	labels := strings.Split(strings.TrimSuffix(fqdn(name), "."), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return strings.Join(labels, ".")
+}
+
+// recordSetLess returns whether the record set identified by name, type
+// and set identifier a goes before b when listed.
+func recordSetLess(a, b [3]string) bool {
+	if ra, rb := reversedName(a[0]), reversedName(b[0]); ra != rb {
+		return ra < rb
+	}
+	if a[1] != b[1] {
+		return a[1] < b[1]
+	}
+	return a[2] < b[2]
+}
+
+// recordSetID returns the name, type and set identifier of a record set.
+func recordSetID(rrs *route53.ResourceRecordSet) [3]string {
+	return [3]string{
+		fqdn(*rrs.Name),
+		*rrs.Type,
+		aws.StringValue(rrs.SetIdentifier),
+	}
+}
+
+// copyRecordSet returns a copy of a record set not sharing any values.
+func copyRecordSet(rrs *route53.ResourceRecordSet) *route53.ResourceRecordSet {
+	copied := *rrs
+	copied.Name = aws.String(fqdn(*rrs.Name))
+	copied.ResourceRecords = nil
+	for _, rr := range rrs.ResourceRecords {
+		copied.ResourceRecords = append(
+			copied.ResourceRecords,
+			&route53.ResourceRecord{Value: aws.String(*rr.Value)},
+		)
+	}
+	if rrs.AliasTarget != nil {
+		target := *rrs.AliasTarget
+		copied.AliasTarget = &target
+	}
+	if rrs.GeoLocation != nil {
+		geo := *rrs.GeoLocation
+		copied.GeoLocation = &geo
+	}
+	return &copied
+}
+
+// AddHostedZone adds a hosted zone named name, with the SOA and NS record
+// sets Route53 creates along with it, and returns its ID. The zone is
+// private if associated with any VPC.
+func (m *Route53Client) AddHostedZone(name string, vpcs ...string) string {
+	params := &route53.CreateHostedZoneInput{
+		Name:            aws.String(name),
+		CallerReference: aws.String(m.newID("ref")),
+	}
+	if len(vpcs) > 0 {
+		params.VPC = newVPC(vpcs[0], "us-east-1")
+	}
+	out, err := m.CreateHostedZone(params)
+	if err != nil {
+		panic(err)
+	}
+	for _, vpc := range vpcs[min(1, len(vpcs)):] {
+		m.vpcs[*out.HostedZone.Id] = append(
+			m.vpcs[*out.HostedZone.Id],
+			newVPC(vpc, "us-east-1"),
+		)
+	}
+	return *out.HostedZone.Id
+}
+
+// min returns the lowest of a and b.
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// AddRecordSets adds record sets to the hosted zone, or replaces those
+// with the same name, type and set identifier, without validating them.
+func (m *Route53Client) AddRecordSets(
+	zoneID string,
+	list ...*route53.ResourceRecordSet,
+) {
+	for _, rrs := range list {
+		m.put(zoneID, rrs)
+	}
+}
+
+// RecordSets returns a copy of the record sets in the hosted zone, in the
+// order Route53 lists them.
+func (m *Route53Client) RecordSets(
+	zoneID string,
+) (list []*route53.ResourceRecordSet) {
+	for _, rrs := range m.records[zoneID] {
+		list = append(list, copyRecordSet(rrs))
+	}
+	return
+}
+
+// put adds a copy of the record set to the hosted zone, keeping the record
+// sets sorted.
+func (m *Route53Client) put(zoneID string, rrs *route53.ResourceRecordSet) {
+	rrs = copyRecordSet(rrs)
+	list := m.records[zoneID]
+	if i, found := m.find(zoneID, rrs); found {
+		list[i] = rrs
+		return
+	}
+	list = append(list, rrs)
+	sort.SliceStable(list, func(i, j int) bool {
+		return recordSetLess(recordSetID(list[i]), recordSetID(list[j]))
+	})
+	m.records[zoneID] = list
+}
+
+// find returns the position in the hosted zone of the record set with the
+// name, type and set identifier of rrs, and whether it exists.
+func (m *Route53Client) find(
+	zoneID string,
+	rrs *route53.ResourceRecordSet,
+) (int, bool) {
+	id := recordSetID(rrs)
+	for i, existing := range m.records[zoneID] {
+		if recordSetID(existing) == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// findZone returns the hosted zone with the ID, which may have the
+// /hostedzone/ prefix.
+func (m *Route53Client) findZone(id string) (*route53.HostedZone, error) {
+	id = "/hostedzone/" + strings.TrimPrefix(id, "/hostedzone/")
+	for _, zone := range m.zones {
+		if *zone.Id == id {
+			return zone, nil
+		}
+	}
+	return nil, awserr.New(
+		route53.ErrCodeNoSuchHostedZone,
+		fmt.Sprintf("No hosted zone found with ID: %s", id),
+		nil,
+	)
+}
+
+// CreateHostedZone mocks route53.CreateHostedZone.
+func (m *Route53Client) CreateHostedZone(
+	params *route53.CreateHostedZoneInput,
+) (out *route53.CreateHostedZoneOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	private := params.VPC != nil
+	zone := &route53.HostedZone{
+		Id:              aws.String("/hostedzone/" + m.newID("Z")),
+		Name:            aws.String(fqdn(*params.Name)),
+		CallerReference: params.CallerReference,
+		Config: &route53.HostedZoneConfig{
+			PrivateZone: aws.Bool(private),
+		},
+		ResourceRecordSetCount: aws.Int64(2),
+	}
+	m.zones = append(m.zones, zone)
+	if private {
+		m.vpcs[*zone.Id] = []*route53.VPC{params.VPC}
+	}
+	servers := []string{}
+	for i := 1; i <= 4; i++ {
+		servers = append(servers, fmt.Sprintf("ns-%d.awsdns.example.", i))
+	}
+	m.put(*zone.Id, &route53.ResourceRecordSet{
+		Name: zone.Name,
+		Type: aws.String("NS"),
+		TTL:  aws.Int64(172800),
+		ResourceRecords: []*route53.ResourceRecord{
+			{Value: aws.String(servers[0])},
+			{Value: aws.String(servers[1])},
+			{Value: aws.String(servers[2])},
+			{Value: aws.String(servers[3])},
+		},
+	})
+	m.put(*zone.Id, &route53.ResourceRecordSet{
+		Name: zone.Name,
+		Type: aws.String("SOA"),
+		TTL:  aws.Int64(900),
+		ResourceRecords: []*route53.ResourceRecord{
+			{Value: aws.String(
+				servers[0] + " awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400",
+			)},
+		},
+	})
+	out = &route53.CreateHostedZoneOutput{
+		HostedZone:    zone,
+		ChangeInfo:    m.newChange(),
+		DelegationSet: &route53.DelegationSet{NameServers: aws.StringSlice(servers)},
+	}
+	if private {
+		out.DelegationSet = nil
+		out.VPC = params.VPC
+	}
+	return
+}
+
+// GetHostedZone mocks route53.GetHostedZone.
+func (m *Route53Client) GetHostedZone(
+	params *route53.GetHostedZoneInput,
+) (out *route53.GetHostedZoneOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	zone, err := m.findZone(*params.Id)
+	if err != nil {
+		return
+	}
+	out = &route53.GetHostedZoneOutput{
+		HostedZone: zone,
+		VPCs:       m.vpcs[*zone.Id],
+	}
+	return
+}
+
+// pageSize returns the size of the pages returned, given the maximum
+// requested and the Route53 limit.
+func (m *Route53Client) pageSize(maxItems *string, limit int) int {
+	size := limit
+	if maxItems != nil {
+		if n, err := strconv.Atoi(*maxItems); err == nil && n > 0 && n < size {
+			size = n
+		}
+	}
+	if m.PageSize > 0 && m.PageSize < size {
+		size = m.PageSize
+	}
+	return size
+}
+
+// ListHostedZones mocks route53.ListHostedZones, with markers being the ID
+// of the first zone of the next page.
+func (m *Route53Client) ListHostedZones(
+	params *route53.ListHostedZonesInput,
+) (out *route53.ListHostedZonesOutput, err error) {
+	start := 0
+	if params.Marker != nil {
+		start = len(m.zones)
+		for i, zone := range m.zones {
+			if *zone.Id == *params.Marker {
+				start = i
+			}
+		}
+	}
+	size := m.pageSize(params.MaxItems, 100)
+	end := min(start+size, len(m.zones))
+	out = &route53.ListHostedZonesOutput{
+		HostedZones: m.zones[start:end],
+		IsTruncated: aws.Bool(end < len(m.zones)),
+		MaxItems:    aws.String(strconv.Itoa(size)),
+		Marker:      params.Marker,
+	}
+	if end < len(m.zones) {
+		out.NextMarker = m.zones[end].Id
+	}
+	return
+}
+
+// ListHostedZonesByName mocks route53.ListHostedZonesByName, listing the
+// zones sorted by their names with the labels reversed, then by ID.
+func (m *Route53Client) ListHostedZonesByName(
+	params *route53.ListHostedZonesByNameInput,
+) (out *route53.ListHostedZonesByNameOutput, err error) {
+	zones := append([]*route53.HostedZone{}, m.zones...)
+	sort.SliceStable(zones, func(i, j int) bool {
+		a, b := reversedName(*zones[i].Name), reversedName(*zones[j].Name)
+		if a != b {
+			return a < b
+		}
+		return *zones[i].Id < *zones[j].Id
+	})
+	start := 0
+	if params.DNSName != nil {
+		start = len(zones)
+		name := reversedName(*params.DNSName)
+		for i, zone := range zones {
+			zoneName := reversedName(*zone.Name)
+			if zoneName > name ||
+				(zoneName == name &&
+					(params.HostedZoneId == nil || *zone.Id >= *params.HostedZoneId)) {
+				start = i
+				break
+			}
+		}
+	}
+	size := m.pageSize(params.MaxItems, 100)
+	end := min(start+size, len(zones))
+	out = &route53.ListHostedZonesByNameOutput{
+		HostedZones:  zones[start:end],
+		IsTruncated:  aws.Bool(end < len(zones)),
+		MaxItems:     aws.String(strconv.Itoa(size)),
+		DNSName:      params.DNSName,
+		HostedZoneId: params.HostedZoneId,
+	}
+	if end < len(zones) {
+		out.NextDNSName = zones[end].Name
+		out.NextHostedZoneId = zones[end].Id
+	}
+	return
+}
+
+// ListResourceRecordSets mocks route53.ListResourceRecordSets, listing
+// the record sets sorted by their names with the labels reversed, then by
+// type and set identifier, from the one given by the start parameters.
+func (m *Route53Client) ListResourceRecordSets(
+	params *route53.ListResourceRecordSetsInput,
+) (out *route53.ListResourceRecordSetsOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	if params.StartRecordType != nil && params.StartRecordName == nil {
+		err = awserr.New(
+			route53.ErrCodeInvalidInput,
+			"StartRecordType requires StartRecordName",
+			nil,
+		)
+		return
+	}
+	zone, err := m.findZone(*params.HostedZoneId)
+	if err != nil {
+		return
+	}
+	list := m.records[*zone.Id]
+	start := 0
+	if params.StartRecordName != nil {
+		from := [3]string{
+			fqdn(*params.StartRecordName),
+			aws.StringValue(params.StartRecordType),
+			aws.StringValue(params.StartRecordIdentifier),
+		}
+		start = len(list)
+		for i, rrs := range list {
+			if !recordSetLess(recordSetID(rrs), from) {
+				start = i
+				break
+			}
+		}
+	}
+	size := m.pageSize(params.MaxItems, maxRecordSetsPage)
+	end := min(start+size, len(list))
+	out = &route53.ListResourceRecordSetsOutput{
+		IsTruncated: aws.Bool(end < len(list)),
+		MaxItems:    aws.String(strconv.Itoa(size)),
+	}
+	for _, rrs := range list[start:end] {
+		out.ResourceRecordSets = append(out.ResourceRecordSets, copyRecordSet(rrs))
+	}
+	if end < len(list) {
+		next := list[end]
+		out.NextRecordName = next.Name
+		out.NextRecordType = next.Type
+		out.NextRecordIdentifier = next.SetIdentifier
+	}
+	return
+}
+
+// ChangeResourceRecordSets mocks route53.ChangeResourceRecordSets. Changes
+// are validated and applied in order, and if any fails none is applied.
+func (m *Route53Client) ChangeResourceRecordSets(
+	params *route53.ChangeResourceRecordSetsInput,
+) (out *route53.ChangeResourceRecordSetsOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	zone, err := m.findZone(*params.HostedZoneId)
+	if err != nil {
+		return
+	}
+	if err = validateBatch(params.ChangeBatch.Changes); err != nil {
+		return
+	}
+	original := m.records[*zone.Id]
+	m.records[*zone.Id] = append([]*route53.ResourceRecordSet{}, original...)
+	for _, change := range params.ChangeBatch.Changes {
+		if err = m.change(zone, change); err != nil {
+			m.records[*zone.Id] = original
+			return
+		}
+	}
+	zone.ResourceRecordSetCount = aws.Int64(int64(len(m.records[*zone.Id])))
+	out = &route53.ChangeResourceRecordSetsOutput{ChangeInfo: m.newChange()}
+	return
+}
+
+// ChangeResourceRecordSetsWithContext mocks
+// route53.ChangeResourceRecordSetsWithContext.
+func (m *Route53Client) ChangeResourceRecordSetsWithContext(
+	ctx aws.Context,
+	params *route53.ChangeResourceRecordSetsInput,
+	opts ...request.Option,
+) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return m.ChangeResourceRecordSets(params)
+}
+
+// validateBatch checks the change batch is within the Route53 limits.
+func validateBatch(changes []*route53.Change) error {
+	records, length := 0, 0
+	for _, change := range changes {
+		r := len(change.ResourceRecordSet.ResourceRecords)
+		l := 0
+		for _, rr := range change.ResourceRecordSet.ResourceRecords {
+			l += len(aws.StringValue(rr.Value))
+		}
+		if r == 0 {
+			r = 1
+		}
+		if *change.Action == route53.ChangeActionUpsert {
+			r, l = 2*r, 2*l
+		}
+		records += r
+		length += l
+	}
+	switch {
+	case records > maxBatchRecords:
+		return invalidChangeBatch(
+			"Number of records limit of %d exceeded.",
+			maxBatchRecords,
+		)
+	case length > maxBatchValueLength:
+		return invalidChangeBatch(
+			"Number of characters limit of %d exceeded.",
+			maxBatchValueLength,
+		)
+	}
+	return nil
+}
+
+// change applies a single change to the record sets of the zone.
+func (m *Route53Client) change(
+	zone *route53.HostedZone,
+	change *route53.Change,
+) error {
+	rrs := change.ResourceRecordSet
+	name := fqdn(*rrs.Name)
+	if name != *zone.Name && !strings.HasSuffix(name, "."+*zone.Name) {
+		return invalidChangeBatch(
+			"RRSet with DNS name %s is not permitted in zone %s",
+			name,
+			*zone.Name,
+		)
+	}
+	if rrs.AliasTarget == nil &&
+		(rrs.TTL == nil || len(rrs.ResourceRecords) == 0) {
+		return invalidChangeBatch(
+			"Invalid request: Expected exactly one of [AliasTarget, all of [TTL, and ResourceRecords]]",
+		)
+	}
+	i, found := m.find(*zone.Id, rrs)
+	switch *change.Action {
+	case route53.ChangeActionCreate:
+		if found {
+			return invalidChangeBatch(
+				"Tried to create resource record set [name='%s', type='%s'] but it already exists",
+				name,
+				*rrs.Type,
+			)
+		}
+		fallthrough
+	case route53.ChangeActionUpsert:
+		if err := m.checkCNAME(zone, rrs); err != nil {
+			return err
+		}
+		m.put(*zone.Id, rrs)
+	case route53.ChangeActionDelete:
+		if !found || !recordSetEqual(m.records[*zone.Id][i], rrs) {
+			return invalidChangeBatch(
+				"Tried to delete resource record set [name='%s', type='%s'] but it was not found",
+				name,
+				*rrs.Type,
+			)
+		}
+		list := m.records[*zone.Id]
+		m.records[*zone.Id] = append(list[:i:i], list[i+1:]...)
+	}
+	return nil
+}
+
+// checkCNAME checks the record set doesn't make a CNAME share its name with
+// record sets of other types.
+func (m *Route53Client) checkCNAME(
+	zone *route53.HostedZone,
+	rrs *route53.ResourceRecordSet,
+) error {
+	name := fqdn(*rrs.Name)
+	for _, existing := range m.records[*zone.Id] {
+		if fqdn(*existing.Name) != name || *existing.Type == *rrs.Type {
+			continue
+		}
+		if *existing.Type == "CNAME" || *rrs.Type == "CNAME" {
+			return invalidChangeBatch(
+				"RRSet of type CNAME with DNS name %s is not permitted as it conflicts with other records with the same DNS name in zone %s",
+				name,
+				*zone.Name,
+			)
+		}
+	}
+	return nil
+}
+
+// recordSetEqual returns whether both record sets have the same data, as
+// Route53 requires for deleting them.
+func recordSetEqual(a, b *route53.ResourceRecordSet) bool {
+	if aws.Int64Value(a.TTL) != aws.Int64Value(b.TTL) ||
+		aws.Int64Value(a.Weight) != aws.Int64Value(b.Weight) ||
+		aws.StringValue(a.Region) != aws.StringValue(b.Region) ||
+		aws.StringValue(a.Failover) != aws.StringValue(b.Failover) ||
+		aws.StringValue(a.HealthCheckId) != aws.StringValue(b.HealthCheckId) ||
+		(a.AliasTarget == nil) != (b.AliasTarget == nil) ||
+		len(a.ResourceRecords) != len(b.ResourceRecords) {
+		return false
+	}
+	if a.AliasTarget != nil &&
+		fqdn(aws.StringValue(a.AliasTarget.DNSName)) !=
+			fqdn(aws.StringValue(b.AliasTarget.DNSName)) {
+		return false
+	}
+	values := map[string]int{}
+	for _, rr := range a.ResourceRecords {
+		values[*rr.Value]++
+	}
+	for _, rr := range b.ResourceRecords {
+		values[*rr.Value]--
+	}
+	for _, count := range values {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// newChange records a new change, PENDING until polled PendingPolls times.
+func (m *Route53Client) newChange() *route53.ChangeInfo {
+	info := &route53.ChangeInfo{
+		Id:     aws.String("/change/" + m.newID("C")),
+		Status: aws.String(route53.ChangeStatusPending),
+	}
+	m.changes[*info.Id] = info
+	if m.PendingPolls <= 0 {
+		info.Status = aws.String(route53.ChangeStatusInsync)
+	}
+	copied := *info
+	return &copied
+}
+
+// GetChange mocks route53.GetChange.
+func (m *Route53Client) GetChange(
+	params *route53.GetChangeInput,
+) (out *route53.GetChangeOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	id := "/change/" + strings.TrimPrefix(*params.Id, "/change/")
+	info, ok := m.changes[id]
+	if !ok {
+		err = awserr.New(
+			route53.ErrCodeNoSuchChange,
+			fmt.Sprintf("A change with the specified change ID does not exist: %s", id),
+			nil,
+		)
+		return
+	}
+	m.polls[id]++
+	if m.polls[id] >= m.PendingPolls {
+		info.Status = aws.String(route53.ChangeStatusInsync)
+	}
+	copied := *info
+	out = &route53.GetChangeOutput{ChangeInfo: &copied}
+	return
+}
+
+// GetChangeWithContext mocks route53.GetChangeWithContext.
+func (m *Route53Client) GetChangeWithContext(
+	ctx aws.Context,
+	params *route53.GetChangeInput,
+	opts ...request.Option,
+) (*route53.GetChangeOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.GetChange(params)
+}
+
+// findVPC returns the position of the VPC with the ID and region of vpc in
+// list, or -1 if not there.
+func findVPC(list []*route53.VPC, vpc *route53.VPC) int {
+	for i, v := range list {
+		if *v.VPCId == *vpc.VPCId && *v.VPCRegion == *vpc.VPCRegion {
+			return i
+		}
+	}
+	return -1
+}
+
+// AssociateVPCWithHostedZone mocks route53.AssociateVPCWithHostedZone. As
+// there are no accounts, VPCs don't need to be authorized.
+func (m *Route53Client) AssociateVPCWithHostedZone(
+	params *route53.AssociateVPCWithHostedZoneInput,
+) (out *route53.AssociateVPCWithHostedZoneOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	zone, err := m.findZone(*params.HostedZoneId)
+	if err != nil {
+		return
+	}
+	switch {
+	case !aws.BoolValue(zone.Config.PrivateZone):
+		err = awserr.New(
+			route53.ErrCodePublicZoneVPCAssociation,
+			"Public hosted zones can't be associated with VPCs",
+			nil,
+		)
+		return
+	case findVPC(m.vpcs[*zone.Id], params.VPC) >= 0:
+		err = awserr.New(
+			route53.ErrCodeConflictingDomainExists,
+			fmt.Sprintf("The VPC %s is already associated with %s", *params.VPC.VPCId, *zone.Id),
+			nil,
+		)
+		return
+	}
+	m.vpcs[*zone.Id] = append(
+		m.vpcs[*zone.Id],
+		newVPC(*params.VPC.VPCId, *params.VPC.VPCRegion),
+	)
+	out = &route53.AssociateVPCWithHostedZoneOutput{ChangeInfo: m.newChange()}
+	return
+}
+
+// DisassociateVPCFromHostedZone mocks route53.DisassociateVPCFromHostedZone.
+func (m *Route53Client) DisassociateVPCFromHostedZone(
+	params *route53.DisassociateVPCFromHostedZoneInput,
+) (out *route53.DisassociateVPCFromHostedZoneOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	zone, err := m.findZone(*params.HostedZoneId)
+	if err != nil {
+		return
+	}
+	list := m.vpcs[*zone.Id]
+	i := findVPC(list, params.VPC)
+	switch {
+	case i < 0:
+		err = awserr.New(
+			route53.ErrCodeVPCAssociationNotFound,
+			fmt.Sprintf("The VPC %s is not associated with %s", *params.VPC.VPCId, *zone.Id),
+			nil,
+		)
+		return
+	case len(list) == 1:
+		err = awserr.New(
+			route53.ErrCodeLastVPCAssociation,
+			"The last VPC associated with a private hosted zone can't be disassociated",
+			nil,
+		)
+		return
+	}
+	m.vpcs[*zone.Id] = append(list[:i:i], list[i+1:]...)
+	out = &route53.DisassociateVPCFromHostedZoneOutput{ChangeInfo: m.newChange()}
+	return
+}
+
+// CreateVPCAssociationAuthorization mocks
+// route53.CreateVPCAssociationAuthorization.
+func (m *Route53Client) CreateVPCAssociationAuthorization(
+	params *route53.CreateVPCAssociationAuthorizationInput,
+) (out *route53.CreateVPCAssociationAuthorizationOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	zone, err := m.findZone(*params.HostedZoneId)
+	if err != nil {
+		return
+	}
+	if findVPC(m.authorized[*zone.Id], params.VPC) < 0 {
+		m.authorized[*zone.Id] = append(
+			m.authorized[*zone.Id],
+			newVPC(*params.VPC.VPCId, *params.VPC.VPCRegion),
+		)
+	}
+	out = &route53.CreateVPCAssociationAuthorizationOutput{
+		HostedZoneId: zone.Id,
+		VPC:          params.VPC,
+	}
+	return
+}
+
+// DeleteVPCAssociationAuthorization mocks
+// route53.DeleteVPCAssociationAuthorization.
+func (m *Route53Client) DeleteVPCAssociationAuthorization(
+	params *route53.DeleteVPCAssociationAuthorizationInput,
+) (out *route53.DeleteVPCAssociationAuthorizationOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	zone, err := m.findZone(*params.HostedZoneId)
+	if err != nil {
+		return
+	}
+	list := m.authorized[*zone.Id]
+	i := findVPC(list, params.VPC)
+	if i < 0 {
+		err = awserr.New(
+			route53.ErrCodeVPCAssociationAuthorizationNotFound,
+			fmt.Sprintf("The VPC %s is not authorized to be associated with %s", *params.VPC.VPCId, *zone.Id),
+			nil,
+		)
+		return
+	}
+	m.authorized[*zone.Id] = append(list[:i:i], list[i+1:]...)
+	out = &route53.DeleteVPCAssociationAuthorizationOutput{}
+	return
+}
+
+// ListVPCAssociationAuthorizations mocks
+// route53.ListVPCAssociationAuthorizations, with tokens being the position
+// of the first VPC of the next page.
+func (m *Route53Client) ListVPCAssociationAuthorizations(
+	params *route53.ListVPCAssociationAuthorizationsInput,
+) (out *route53.ListVPCAssociationAuthorizationsOutput, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	zone, err := m.findZone(*params.HostedZoneId)
+	if err != nil {
+		return
+	}
+	list := m.authorized[*zone.Id]
+	start := 0
+	if params.NextToken != nil {
+		if start, err = strconv.Atoi(*params.NextToken); err != nil {
+			return
+		}
+	}
+	end := min(start+m.pageSize(params.MaxResults, 50), len(list))
+	out = &route53.ListVPCAssociationAuthorizationsOutput{
+		HostedZoneId: zone.Id,
+		VPCs:         list[start:end],
+	}
+	if end < len(list) {
+		out.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return
+}
+
+// NewVPC returns the VPC with ID id in region.
+func newVPC(id, region string) *route53.VPC {
+	return &route53.VPC{
+		VPCId:     aws.String(id),
+		VPCRegion: aws.String(region),
+	}
+}
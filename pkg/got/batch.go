@@ -0,0 +1,56 @@
+package got
+
+import (
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+const (
+	// MaxBatchRecords is the maximum amount of ResourceRecord elements
+	// Route53 accepts in a single change batch.
+	MaxBatchRecords = 1000
+	// MaxBatchValueLength is the maximum amount of characters Route53
+	// accepts among all the values in a single change batch.
+	MaxBatchValueLength = 32000
+)
+
+// changeCost returns the amount of records and value characters a change
+// accounts for in a batch. UPSERT changes count twice, as Route53 handles
+// them as a DELETE plus a CREATE.
+func changeCost(change *route53.Change) (records, length int) {
+	records = len(change.ResourceRecordSet.ResourceRecords)
+	if records == 0 {
+		records = 1
+	}
+	for _, rr := range change.ResourceRecordSet.ResourceRecords {
+		length += len(*rr.Value)
+	}
+	if *change.Action == "UPSERT" {
+		records *= 2
+		length *= 2
+	}
+	return
+}
+
+// SplitChanges splits the list of changes in batches within the Route53
+// limits, keeping their order. A change exceeding the limits on its own is
+// sent in a batch of its own, for Route53 to report the error.
+func SplitChanges(changes []*route53.Change) (batches [][]*route53.Change) {
+	batch := []*route53.Change{}
+	records, length := 0, 0
+	for _, change := range changes {
+		r, l := changeCost(change)
+		if len(batch) > 0 &&
+			(records+r > MaxBatchRecords || length+l > MaxBatchValueLength) {
+			batches = append(batches, batch)
+			batch = []*route53.Change{}
+			records, length = 0, 0
+		}
+		batch = append(batch, change)
+		records += r
+		length += l
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package got
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// newChanges returns amount changes with the action, each with a record of
+// length characters.
+func newChanges(action string, amount, length int) (changes []*route53.Change) {
+	for i := 0; i < amount; i++ {
+		changes = append(changes, &route53.Change{
+			Action: aws.String(action),
+			ResourceRecordSet: newRecordSet(
+				fmt.Sprintf("r%d.example.com.", i),
+				"TXT",
+				300,
+				strings.Repeat("a", length),
+			),
+		})
+	}
+	return
+}
+
+var splitChangesTests = []struct {
+	name     string
+	changes  []*route53.Change
+	expected []int
+}{
+	{"empty", nil, nil},
+	{"single batch", newChanges("CREATE", 1000, 1), []int{1000}},
+	{"records limit", newChanges("CREATE", 1001, 1), []int{1000, 1}},
+	{"upserts count twice", newChanges("UPSERT", 501, 1), []int{500, 1}},
+	{"value length limit", newChanges("CREATE", 5, 10000), []int{3, 2}},
+	{"oversized change", newChanges("CREATE", 2, 40000), []int{1, 1}},
+}
+
+func TestSplitChanges(t *testing.T) {
+	for _, tt := range splitChangesTests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := SplitChanges(tt.changes)
+			if len(batches) != len(tt.expected) {
+				t.Fatalf(
+					"Expected %d batches, received %d",
+					len(tt.expected),
+					len(batches),
+				)
+			}
+			next := 0
+			for i, batch := range batches {
+				if len(batch) != tt.expected[i] {
+					t.Errorf(
+						"Expected %d changes in batch %d, received %d",
+						tt.expected[i],
+						i,
+						len(batch),
+					)
+				}
+				for _, change := range batch {
+					if change != tt.changes[next] {
+						t.Errorf("Change %d is out of order", next)
+					}
+					next++
+				}
+			}
+		})
+	}
+}
+
+func TestApplyChangesInBatches(t *testing.T) {
+	out, err := ApplyChanges(
+		newChanges("UPSERT", 1200, 1),
+		aws.String("test"),
+		&mockRoute53Client{},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(out) != 3 {
+		t.Errorf("Expected 3 batches, received %d", len(out))
+	}
+}
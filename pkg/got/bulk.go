@@ -0,0 +1,251 @@
+package got
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/miekg/dns"
+)
+
+// ReadRecords reads records in CSV or JSON format, as written by WriteCSV
+// and WriteJSON. CSV input has a header naming its columns, name, type,
+// ttl and value, and optionally set_identifier and alias, and a row per
+// value. Consecutive or not, the rows of the same record set are merged.
+func ReadRecords(r io.Reader, format string) (records []Record, err error) {
+	switch format {
+	case "csv":
+		return readRecordsCSV(r)
+	case "json":
+		err = json.NewDecoder(r).Decode(&records)
+		return
+	}
+	err = fmt.Errorf("unknown input format %s", format)
+	return
+}
+
+// readRecordsCSV reads records in CSV format.
+func readRecordsCSV(r io.Reader) (records []Record, err error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		switch column {
+		case "name", "type", "ttl", "value", "set_identifier", "alias":
+			columns[column] = i
+		default:
+			err = fmt.Errorf("unknown column %s", column)
+			return
+		}
+	}
+	for _, column := range []string{"name", "type", "value"} {
+		if _, ok := columns[column]; !ok {
+			err = fmt.Errorf("missing column %s", column)
+			return
+		}
+	}
+	field := func(row []string, column string) string {
+		if i, ok := columns[column]; ok {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	index := map[string]int{}
+	for line := 2; ; line++ {
+		var row []string
+		row, err = cr.Read()
+		if err == io.EOF {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		rec := Record{
+			Name:          field(row, "name"),
+			Type:          strings.ToUpper(field(row, "type")),
+			SetIdentifier: field(row, "set_identifier"),
+			Alias:         field(row, "alias"),
+		}
+		if ttl := field(row, "ttl"); ttl != "" {
+			rec.TTL, err = strconv.ParseInt(ttl, 10, 64)
+			if err != nil {
+				err = fmt.Errorf("line %d: invalid TTL %s", line, ttl)
+				return
+			}
+		}
+		if value := field(row, "value"); value != "" {
+			rec.Values = []string{value}
+		}
+		key := strings.ToLower(Fqdn(rec.Name)) + " " + rec.Type + " " +
+			rec.SetIdentifier
+		i, found := index[key]
+		if !found {
+			index[key] = len(records)
+			records = append(records, rec)
+			continue
+		}
+		if records[i].TTL != rec.TTL {
+			err = fmt.Errorf(
+				"line %d: TTL of %s %s differs from previous rows",
+				line,
+				rec.Name,
+				rec.Type,
+			)
+			return
+		}
+		records[i].Values = append(records[i].Values, rec.Values...)
+	}
+}
+
+// BulkUpsertChangeList returns the changes upserting the records in zone,
+// along with an error per invalid record. Names not ending in a dot are
+// relative to the zone, and records without TTL get ttl. Alias records and
+// routing policies aren't supported.
+func BulkUpsertChangeList(
+	records []Record,
+	zone string,
+	ttl int64,
+) (changes []*route53.Change, errs []error) {
+	seen := map[string]bool{}
+	for i, rec := range records {
+		rrs, err := bulkRecordSet(rec, zone)
+		if err == nil {
+			if rrs.TTL == nil {
+				rrs.TTL = aws.Int64(ttl)
+			}
+			err = validateBulkUpsert(rrs)
+		}
+		if err == nil && seen[recordSetKey(rrs)] {
+			err = fmt.Errorf("duplicated")
+		}
+		if err != nil {
+			errs = append(errs, bulkError(i, rec, err))
+			continue
+		}
+		seen[recordSetKey(rrs)] = true
+		changes = append(changes, &route53.Change{
+			Action:            aws.String(route53.ChangeActionUpsert),
+			ResourceRecordSet: rrs,
+		})
+	}
+	return
+}
+
+// BulkDeleteChangeList returns the changes deleting the records from zone,
+// whose record sets are currently those in current, along with an error
+// per invalid record. Records without values delete the record set
+// whatever its values are, while those with values are only deleted if
+// they are the current ones.
+func BulkDeleteChangeList(
+	records []Record,
+	zone string,
+	current []*route53.ResourceRecordSet,
+) (changes []*route53.Change, errs []error) {
+	existing := map[string]*route53.ResourceRecordSet{}
+	for _, rrs := range current {
+		existing[recordSetKey(rrs)] = rrs
+	}
+	seen := map[string]bool{}
+	for i, rec := range records {
+		rrs, err := bulkRecordSet(rec, zone)
+		var old *route53.ResourceRecordSet
+		if err == nil {
+			old = existing[recordSetKey(rrs)]
+			switch {
+			case old == nil:
+				err = fmt.Errorf("not found")
+			case seen[recordSetKey(rrs)]:
+				err = fmt.Errorf("duplicated")
+			case len(rec.Values) > 0 &&
+				strings.Join(recordSetValues(rrs), " ") !=
+					strings.Join(recordSetValues(old), " "):
+				err = fmt.Errorf(
+					"values differ from the current ones, %s",
+					strings.Join(formatValues(old), ", "),
+				)
+			}
+		}
+		if err != nil {
+			errs = append(errs, bulkError(i, rec, err))
+			continue
+		}
+		seen[recordSetKey(rrs)] = true
+		changes = append(changes, &route53.Change{
+			Action:            aws.String(route53.ChangeActionDelete),
+			ResourceRecordSet: old,
+		})
+	}
+	return
+}
+
+// bulkRecordSet returns the record set described by a record read in bulk.
+func bulkRecordSet(
+	rec Record,
+	zone string,
+) (rrs *route53.ResourceRecordSet, err error) {
+	switch {
+	case rec.Name == "":
+		err = fmt.Errorf("no name")
+		return
+	case rec.Type == "":
+		err = fmt.Errorf("no type")
+		return
+	case rec.Alias != "":
+		err = fmt.Errorf("alias records aren't supported")
+		return
+	}
+	typ := strings.ToUpper(rec.Type)
+	if _, ok := dns.StringToType[typ]; !ok {
+		err = fmt.Errorf("unknown type %s", rec.Type)
+		return
+	}
+	name := absoluteName(rec.Name, zone)
+	apex := strings.ToLower(Fqdn(zone))
+	lower := strings.ToLower(name)
+	if lower != apex && !strings.HasSuffix(lower, "."+apex) {
+		err = fmt.Errorf("not in zone %s", zone)
+		return
+	}
+	rrs = &route53.ResourceRecordSet{
+		Name:            aws.String(name),
+		Type:            aws.String(typ),
+		ResourceRecords: NewResourceRecordList(rec.Values),
+	}
+	if rec.TTL > 0 {
+		rrs.TTL = aws.Int64(rec.TTL)
+	}
+	if rec.SetIdentifier != "" {
+		rrs.SetIdentifier = aws.String(rec.SetIdentifier)
+	}
+	return
+}
+
+// validateBulkUpsert checks a record set read in bulk can be upserted.
+func validateBulkUpsert(rrs *route53.ResourceRecordSet) error {
+	switch {
+	case rrs.SetIdentifier != nil:
+		return fmt.Errorf("routing policies aren't supported")
+	case len(rrs.ResourceRecords) == 0:
+		return fmt.Errorf("no values")
+	case aws.Int64Value(rrs.TTL) <= 0:
+		return fmt.Errorf("no TTL")
+	}
+	if _, err := rrsFromRecordSet(rrs); err != nil {
+		return fmt.Errorf("invalid value: %s", err)
+	}
+	return nil
+}
+
+// bulkError returns err about the i-th record read in bulk.
+func bulkError(i int, rec Record, err error) error {
+	return fmt.Errorf("record %d %s %s: %s", i+1, rec.Name, rec.Type, err)
+}
@@ -0,0 +1,255 @@
+package got
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func TestReadRecords(t *testing.T) {
+	tcs := []struct {
+		name     string
+		format   string
+		input    string
+		expected []Record
+		err      string
+	}{
+		{
+			name:   "CSV",
+			format: "csv",
+			input: `name,type,ttl,value
+www,a,300,10.0.0.1
+mail.example.com.,MX,,10 mx.example.com.
+www,A,300,10.0.0.2
+`,
+			expected: []Record{
+				{Name: "www", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}},
+				{Name: "mail.example.com.", Type: "MX", Values: []string{"10 mx.example.com."}},
+			},
+		},
+		{
+			name:   "CSV with set identifiers and columns reordered",
+			format: "csv",
+			input: `value,type,name,set_identifier
+10.0.0.1,A,www,blue
+10.0.0.2,A,www,green
+`,
+			expected: []Record{
+				{Name: "www", Type: "A", SetIdentifier: "blue", Values: []string{"10.0.0.1"}},
+				{Name: "www", Type: "A", SetIdentifier: "green", Values: []string{"10.0.0.2"}},
+			},
+		},
+		{
+			name:   "CSV with unknown column",
+			format: "csv",
+			input:  "name,type,weight,value\n",
+			err:    "unknown column weight",
+		},
+		{
+			name:   "CSV without values",
+			format: "csv",
+			input:  "name,type,ttl\n",
+			err:    "missing column value",
+		},
+		{
+			name:   "CSV with wrong TTL",
+			format: "csv",
+			input:  "name,type,ttl,value\nwww,A,5m,10.0.0.1\n",
+			err:    "line 2: invalid TTL 5m",
+		},
+		{
+			name:   "CSV with different TTLs",
+			format: "csv",
+			input:  "name,type,ttl,value\nwww,A,60,10.0.0.1\nwww,A,300,10.0.0.2\n",
+			err:    "line 3: TTL of www A differs from previous rows",
+		},
+		{
+			name:   "JSON",
+			format: "json",
+			input:  `[{"name": "www", "type": "A", "ttl": 60, "values": ["10.0.0.1"]}]`,
+			expected: []Record{
+				{Name: "www", Type: "A", TTL: 60, Values: []string{"10.0.0.1"}},
+			},
+		},
+		{
+			name:   "Unknown format",
+			format: "xml",
+			err:    "unknown input format xml",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := ReadRecords(strings.NewReader(tc.input), tc.format)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error %q, received %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			if len(records) != len(tc.expected) {
+				t.Fatalf("Expected %v, received %v", tc.expected, records)
+			}
+			for i := range records {
+				if strings.Join(records[i].Values, ",") != strings.Join(tc.expected[i].Values, ",") ||
+					records[i].Name != tc.expected[i].Name ||
+					records[i].Type != tc.expected[i].Type ||
+					records[i].TTL != tc.expected[i].TTL ||
+					records[i].SetIdentifier != tc.expected[i].SetIdentifier {
+					t.Errorf("Expected %v, received %v", tc.expected[i], records[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadRecordsWrittenCSV(t *testing.T) {
+	list := []*route53.ResourceRecordSet{
+		newRecordSet("\\052.example.com.", "CNAME", 300, "www.example.com."),
+		newRecordSet("www.example.com.", "A", 60, "10.0.0.1", "10.0.0.2"),
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteCSV(buf, list); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	records, err := ReadRecords(buf, "csv")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	changes, errs := BulkUpsertChangeList(records, "example.com", 30)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+	for i, change := range changes {
+		if !recordSetEqual(change.ResourceRecordSet, list[i]) ||
+			recordSetKey(change.ResourceRecordSet) != recordSetKey(list[i]) {
+			t.Errorf("Expected %s, received %s", list[i], change.ResourceRecordSet)
+		}
+	}
+}
+
+func TestReadRecordsWrittenCSVRoutingAndAlias(t *testing.T) {
+	blue := newRecordSet("api.example.com.", "A", 60, "10.0.0.1")
+	blue.SetIdentifier = aws.String("blue")
+	blue.Weight = aws.Int64(1)
+	list := []*route53.ResourceRecordSet{
+		blue,
+		{
+			Name: aws.String("lb.example.com."),
+			Type: aws.String("A"),
+			AliasTarget: &route53.AliasTarget{
+				DNSName:      aws.String("lb.elb.amazonaws.com."),
+				HostedZoneId: aws.String("Z35SXDOTRQ7X7K"),
+			},
+		},
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteCSV(buf, list); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	records, err := ReadRecords(buf, "csv")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := []Record{
+		{Name: "api.example.com.", Type: "A", SetIdentifier: "blue", TTL: 60, Values: []string{"10.0.0.1"}},
+		{Name: "lb.example.com.", Type: "A", Alias: "lb.elb.amazonaws.com."},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %v, received %v", expected, records)
+	}
+	for i := range records {
+		if strings.Join(records[i].Values, ",") != strings.Join(expected[i].Values, ",") ||
+			records[i].Name != expected[i].Name ||
+			records[i].Type != expected[i].Type ||
+			records[i].TTL != expected[i].TTL ||
+			records[i].SetIdentifier != expected[i].SetIdentifier ||
+			records[i].Alias != expected[i].Alias {
+			t.Errorf("Expected %v, received %v", expected[i], records[i])
+		}
+	}
+	changes, errs := BulkDeleteChangeList(records[:1], "example.com", list)
+	if len(errs) > 0 || len(changes) != 1 || changes[0].ResourceRecordSet != blue {
+		t.Errorf("Unexpected deletion %v, %v", changes, errs)
+	}
+}
+
+func TestBulkUpsertChangeList(t *testing.T) {
+	records := []Record{
+		{Name: "www", Type: "a", Values: []string{"10.0.0.1"}},
+		{Name: "@", Type: "MX", TTL: 300, Values: []string{"10 mx.example.com."}},
+		{Name: "www.example.net.", Type: "A", Values: []string{"10.0.0.1"}},
+		{Name: "api", Type: "A", Values: []string{"not an address"}},
+		{Name: "WWW", Type: "A", Values: []string{"10.0.0.2"}},
+		{Name: "db", Type: "AAA", Values: []string{"::1"}},
+		{Name: "empty", Type: "A"},
+		{Name: "lb", Type: "A", Alias: "lb.elb.amazonaws.com."},
+		{Name: "blue", Type: "A", SetIdentifier: "blue", Values: []string{"10.0.0.1"}},
+	}
+	changes, errs := BulkUpsertChangeList(records, "example.com", 60)
+	expected := []string{
+		"record 3 www.example.net. A: not in zone example.com",
+		"record 4 api A: invalid value: dns: bad A A: \"not\" at line: 1:29",
+		"record 5 WWW A: duplicated",
+		"record 6 db AAA: unknown type AAA",
+		"record 7 empty A: no values",
+		"record 8 lb A: alias records aren't supported",
+		"record 9 blue A: routing policies aren't supported",
+	}
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected errors:\n%s", strings.Join(messages, "\n"))
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteChanges(buf, changes); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	out := `UPSERT www.example.com. A  60  10.0.0.1
+UPSERT example.com.     MX 300 10 mx.example.com.
+`
+	if buf.String() != out {
+		t.Errorf("Unexpected changes:\n%s", buf.String())
+	}
+}
+
+func TestBulkDeleteChangeList(t *testing.T) {
+	current := []*route53.ResourceRecordSet{
+		newRecordSet("www.example.com.", "A", 60, "10.0.0.1", "10.0.0.2"),
+		newRecordSet("mail.example.com.", "MX", 300, "10 mx.example.com."),
+		newRecordSet("api.example.com.", "A", 60, "10.0.0.3"),
+	}
+	records := []Record{
+		{Name: "www", Type: "A", Values: []string{"10.0.0.2", "10.0.0.1"}},
+		{Name: "mail.example.com.", Type: "MX"},
+		{Name: "api", Type: "A", Values: []string{"10.0.0.4"}},
+		{Name: "ftp", Type: "A"},
+		{Name: "mail", Type: "MX"},
+	}
+	changes, errs := BulkDeleteChangeList(records, "example.com", current)
+	expected := []string{
+		"record 3 api A: values differ from the current ones, 10.0.0.3",
+		"record 4 ftp A: not found",
+		"record 5 mail MX: duplicated",
+	}
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected errors:\n%s", strings.Join(messages, "\n"))
+	}
+	if len(changes) != 2 ||
+		changes[0].ResourceRecordSet != current[0] ||
+		changes[1].ResourceRecordSet != current[1] ||
+		*changes[0].Action != "DELETE" {
+		t.Errorf("Unexpected changes %v", changes)
+	}
+}
@@ -0,0 +1,161 @@
+package got
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// Policies on record sets copied that already exist in the destination.
+const (
+	// ConflictSkip keeps the existing record set.
+	ConflictSkip = "skip"
+	// ConflictOverwrite replaces the existing record set.
+	ConflictOverwrite = "overwrite"
+	// ConflictFail refuses to copy anything.
+	ConflictFail = "fail"
+)
+
+// ZoneCopy copies record sets from a zone to another one, rewriting the
+// zone suffix of their names and of the targets in the zone.
+type ZoneCopy struct {
+	From string
+	To   string
+	// FromZoneID and ToZoneID are the IDs of the hosted zones, if any, used
+	// to rewrite alias targets in the zone.
+	FromZoneID string
+	ToZoneID   string
+	// Conflict is the policy on record sets existing in the destination
+	// with different values.
+	Conflict string
+}
+
+// NewZoneCopy returns the ZoneCopy from zone from to zone to, with the
+// conflict policy.
+func NewZoneCopy(from, to, conflict string) (c *ZoneCopy, err error) {
+	switch conflict {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		err = fmt.Errorf("unknown conflict policy %s", conflict)
+		return
+	}
+	c = &ZoneCopy{
+		From:     from,
+		To:       to,
+		Conflict: conflict,
+	}
+	return
+}
+
+// Changes returns the changes copying the record sets in source into the
+// destination, whose record sets are currently those in current. The SOA
+// and the NS records at the apex of the source zone are never copied, nor
+// are the NS records delegating its subdomains, as their name servers
+// serve the subdomains of the source zone rather than those of the
+// destination. Those must be delegated separately. Record sets already in
+// the destination with the same values are left alone, while the rest are
+// handled according to the conflict policy, and returned as skipped if the
+// policy is to skip them.
+func (c *ZoneCopy) Changes(
+	source []*route53.ResourceRecordSet,
+	current []*route53.ResourceRecordSet,
+) (changes []*route53.Change, skipped []*route53.ResourceRecordSet, err error) {
+	existing := map[string]*route53.ResourceRecordSet{}
+	for _, rrs := range current {
+		existing[recordSetKey(rrs)] = rrs
+	}
+	for _, rrs := range RemoveZoneAuthority(source, c.From) {
+		if *rrs.Type == "NS" {
+			continue
+		}
+		rrs = c.RecordSet(rrs)
+		old, found := existing[recordSetKey(rrs)]
+		action := route53.ChangeActionCreate
+		switch {
+		case found && recordSetEqual(old, rrs):
+			continue
+		case found && c.Conflict == ConflictSkip:
+			skipped = append(skipped, rrs)
+			continue
+		case found && c.Conflict == ConflictFail:
+			err = fmt.Errorf(
+				"record %s %s already exists in %s",
+				*rrs.Name,
+				*rrs.Type,
+				c.To,
+			)
+			return nil, nil, err
+		case found:
+			action = route53.ChangeActionUpsert
+		}
+		changes = append(changes, &route53.Change{
+			Action:            aws.String(action),
+			ResourceRecordSet: rrs,
+		})
+	}
+	return
+}
+
+// RecordSet returns a copy of the record set with the names in the source
+// zone, its own and those of CNAME, MX, SRV and alias targets, moved to the
+// destination zone.
+func (c *ZoneCopy) RecordSet(
+	rrs *route53.ResourceRecordSet,
+) *route53.ResourceRecordSet {
+	copied := *rrs
+	copied.Name = aws.String(c.rewrite(*rrs.Name))
+	copied.ResourceRecords = nil
+	for _, rr := range rrs.ResourceRecords {
+		value := *rr.Value
+		switch *rrs.Type {
+		case "CNAME":
+			value = c.rewrite(value)
+		case "MX", "SRV":
+			fields := strings.Fields(value)
+			if len(fields) > 0 {
+				fields[len(fields)-1] = c.rewrite(fields[len(fields)-1])
+				value = strings.Join(fields, " ")
+			}
+		}
+		copied.ResourceRecords = append(
+			copied.ResourceRecords,
+			&route53.ResourceRecord{Value: aws.String(value)},
+		)
+	}
+	if rrs.AliasTarget != nil {
+		target := *rrs.AliasTarget
+		if c.FromZoneID != "" && c.ToZoneID != "" &&
+			shortZoneID(aws.StringValue(target.HostedZoneId)) ==
+				shortZoneID(c.FromZoneID) {
+			target.DNSName = aws.String(
+				c.rewrite(aws.StringValue(target.DNSName)),
+			)
+			target.HostedZoneId = aws.String(shortZoneID(c.ToZoneID))
+		}
+		copied.AliasTarget = &target
+	}
+	return &copied
+}
+
+// rewrite returns name with the suffix of the source zone replaced with the
+// destination zone, if it's in the source zone.
+func (c *ZoneCopy) rewrite(name string) string {
+	from := strings.ToLower(Fqdn(c.From))
+	fqdn := Fqdn(name)
+	lower := strings.ToLower(fqdn)
+	switch {
+	case lower == from:
+		return Fqdn(c.To)
+	case strings.HasSuffix(lower, "."+from):
+		return fqdn[:len(fqdn)-len(from)] + Fqdn(c.To)
+	}
+	return name
+}
+
+// shortZoneID returns the hosted zone ID without the /hostedzone/ prefix
+// some API responses add to it.
+func shortZoneID(id string) string {
+	return strings.TrimPrefix(id, "/hostedzone/")
+}
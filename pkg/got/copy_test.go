@@ -0,0 +1,113 @@
+package got
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var copySource = []*route53.ResourceRecordSet{
+	newRecordSet("old.example.com.", "SOA", 900, "ns1.old.example.com. hostmaster.old.example.com. 1 7200 900 1209600 86400"),
+	newRecordSet("old.example.com.", "NS", 172800, "ns1.old.example.com."),
+	newRecordSet("dev.old.example.com.", "NS", 172800, "ns-9.awsdns-9.org."),
+	newRecordSet("old.example.com.", "MX", 300, "10 mail.old.example.com.", "20 mx.example.net."),
+	newRecordSet("www.old.example.com.", "CNAME", 300, "web.old.example.com."),
+	newRecordSet("ext.old.example.com.", "CNAME", 300, "cdn.example.net."),
+	newRecordSet("_sip._tcp.Old.Example.com.", "SRV", 300, "10 5 5060 sip.old.example.com."),
+	newRecordSet("mail.old.example.com.", "A", 300, "10.0.0.25"),
+	newRecordSet("txt.old.example.com.", "TXT", 300, "\"old.example.com\""),
+	{
+		Name: aws.String("lb.old.example.com."),
+		Type: aws.String("A"),
+		AliasTarget: &route53.AliasTarget{
+			DNSName:      aws.String("www.old.example.com."),
+			HostedZoneId: aws.String("ZOLD"),
+		},
+	},
+}
+
+var copyCurrent = []*route53.ResourceRecordSet{
+	newRecordSet("new.example.com.", "NS", 172800, "ns-1.awsdns-1.com."),
+	newRecordSet("mail.new.example.com.", "A", 300, "10.0.0.25"),
+	newRecordSet("txt.new.example.com.", "TXT", 300, "\"staging\""),
+}
+
+var copyCases = []struct {
+	conflict string
+	expected string
+	skipped  int
+	err      string
+}{
+	{
+		conflict: ConflictSkip,
+		expected: `CREATE new.example.com.           MX    300 10 mail.new.example.com., 20 mx.example.net.
+CREATE www.new.example.com.       CNAME 300 web.new.example.com.
+CREATE ext.new.example.com.       CNAME 300 cdn.example.net.
+CREATE _sip._tcp.new.example.com. SRV   300 10 5 5060 sip.new.example.com.
+CREATE lb.new.example.com.        A     -   ALIAS www.new.example.com.
+`,
+		skipped: 1,
+	},
+	{
+		conflict: ConflictOverwrite,
+		expected: `CREATE new.example.com.           MX    300 10 mail.new.example.com., 20 mx.example.net.
+CREATE www.new.example.com.       CNAME 300 web.new.example.com.
+CREATE ext.new.example.com.       CNAME 300 cdn.example.net.
+CREATE _sip._tcp.new.example.com. SRV   300 10 5 5060 sip.new.example.com.
+UPSERT txt.new.example.com.       TXT   300 "old.example.com"
+CREATE lb.new.example.com.        A     -   ALIAS www.new.example.com.
+`,
+	},
+	{
+		conflict: ConflictFail,
+		err:      "record txt.new.example.com. TXT already exists in new.example.com",
+	},
+}
+
+func TestZoneCopyChanges(t *testing.T) {
+	for _, tc := range copyCases {
+		t.Run(tc.conflict, func(t *testing.T) {
+			c, err := NewZoneCopy("old.example.com", "new.example.com", tc.conflict)
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			c.FromZoneID = "/hostedzone/ZOLD"
+			c.ToZoneID = "/hostedzone/ZNEW"
+			changes, skipped, err := c.Changes(copySource, copyCurrent)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error %q, received %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			buf := &bytes.Buffer{}
+			if err = WriteChanges(buf, changes); err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("Unexpected changes:\n%s", buf.String())
+			}
+			if len(skipped) != tc.skipped {
+				t.Errorf("Expected %d skipped, received %v", tc.skipped, skipped)
+			}
+			alias := changes[len(changes)-1].ResourceRecordSet.AliasTarget
+			if *alias.HostedZoneId != "ZNEW" {
+				t.Errorf("Expected alias in the new zone, received %s", *alias.HostedZoneId)
+			}
+			if *copySource[len(copySource)-1].AliasTarget.HostedZoneId != "ZOLD" {
+				t.Error("Source record set was modified")
+			}
+		})
+	}
+}
+
+func TestNewZoneCopyUnknownPolicy(t *testing.T) {
+	if _, err := NewZoneCopy("a.com", "b.com", "merge"); err == nil {
+		t.Error("Expected unknown policy to fail")
+	}
+}
@@ -0,0 +1,263 @@
+package got
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// Steps of a Cutover, in the order they are performed.
+const (
+	CutoverLowerTTL   = "lower-ttl"
+	CutoverExpireTTL  = "expire-ttl"
+	CutoverSwap       = "swap"
+	CutoverVerify     = "verify"
+	CutoverRestoreTTL = "restore-ttl"
+	CutoverDone       = "done"
+)
+
+// cutoverSleep waits for the old TTL to expire, or ctx to be done. It's
+// replaced in tests.
+var cutoverSleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Cutover describes the migration of a record set to new values, lowering
+// its TTL beforehand so clients stop using the old values as soon as
+// possible. Its state is kept after every step, so it can be resumed if
+// interrupted.
+type Cutover struct {
+	Zone        string    `json:"zone"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Values      []string  `json:"values"`
+	OldValues   []string  `json:"old_values"`
+	OriginalTTL int64     `json:"original_ttl"`
+	LowTTL      int64     `json:"low_ttl"`
+	Step        string    `json:"step"`
+	WaitUntil   time.Time `json:"wait_until,omitempty"`
+}
+
+// NewCutover returns the Cutover of the record set with name and type among
+// the current ones to the values, using lowTTL while migrating. Only record
+// sets with the simple routing policy can be cut over.
+func NewCutover(
+	zone string,
+	name string,
+	typ string,
+	values []string,
+	lowTTL int64,
+	current []*route53.ResourceRecordSet,
+) (c *Cutover, err error) {
+	rrs := findRecordSet(current, name, typ)
+	if rrs == nil {
+		err = fmt.Errorf("record %s %s not found", name, typ)
+		return
+	}
+	if !RoutingOf(rrs).IsSimple() {
+		err = fmt.Errorf(
+			"record %s %s uses a routing policy or is an alias",
+			name,
+			typ,
+		)
+		return
+	}
+	c = &Cutover{
+		Zone:        zone,
+		Name:        *rrs.Name,
+		Type:        *rrs.Type,
+		Values:      values,
+		OldValues:   NewRecord(rrs).Values,
+		OriginalTTL: *rrs.TTL,
+		LowTTL:      lowTTL,
+		Step:        CutoverLowerTTL,
+	}
+	if c.OriginalTTL <= c.LowTTL {
+		c.LowTTL = c.OriginalTTL
+		c.Step = CutoverSwap
+	}
+	return
+}
+
+// LoadCutover reads the state of a Cutover saved in path.
+func LoadCutover(path string) (c *Cutover, err error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	c = &Cutover{}
+	if err = json.Unmarshal(content, c); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Save writes the state of the Cutover to path.
+func (c *Cutover) Save(path string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+// HasValues returns whether the Cutover moves the record set to values, in
+// any order.
+func (c *Cutover) HasValues(values []string) bool {
+	a := &route53.ResourceRecordSet{
+		Type:            aws.String(c.Type),
+		ResourceRecords: NewResourceRecordList(c.Values),
+	}
+	b := &route53.ResourceRecordSet{
+		Type:            aws.String(c.Type),
+		ResourceRecords: NewResourceRecordList(values),
+	}
+	return equalValues(recordSetValues(a), recordSetValues(b))
+}
+
+// Run performs the remaining steps of the Cutover through the provider,
+// waiting for every change with w, and calling save after each step. Steps
+// only UPSERT record sets, so they can be safely repeated when resuming.
+func (c *Cutover) Run(
+	ctx context.Context,
+	p Provider,
+	w *Waiter,
+	save func(*Cutover) error,
+) (err error) {
+	for c.Step != CutoverDone {
+		switch c.Step {
+		case CutoverLowerTTL:
+			if err = c.upsert(ctx, p, w, c.OldValues, c.LowTTL); err != nil {
+				return
+			}
+			c.WaitUntil = time.Now().Add(
+				time.Duration(c.OriginalTTL) * time.Second,
+			)
+			c.Step = CutoverExpireTTL
+		case CutoverExpireTTL:
+			if err = cutoverSleep(ctx, time.Until(c.WaitUntil)); err != nil {
+				return
+			}
+			c.Step = CutoverSwap
+		case CutoverSwap:
+			if err = c.upsert(ctx, p, w, c.Values, c.LowTTL); err != nil {
+				return
+			}
+			c.Step = CutoverVerify
+		case CutoverVerify:
+			if err = c.verify(p); err != nil {
+				return
+			}
+			c.Step = CutoverRestoreTTL
+		case CutoverRestoreTTL:
+			if err = c.upsert(ctx, p, w, c.Values, c.OriginalTTL); err != nil {
+				return
+			}
+			c.Step = CutoverDone
+		default:
+			return fmt.Errorf("unknown cutover step %s", c.Step)
+		}
+		if err = save(c); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// upsert sets the values and ttl of the record set, waiting for the change
+// to be applied.
+func (c *Cutover) upsert(
+	ctx context.Context,
+	p Provider,
+	w *Waiter,
+	values []string,
+	ttl int64,
+) error {
+	ids, err := p.Apply(UpsertChangeList(
+		NewResourceRecordList(values),
+		ttl,
+		c.Name,
+		c.Type,
+		nil,
+	))
+	if err != nil {
+		return err
+	}
+	return w.Wait(ctx, p, ids...)
+}
+
+// verify checks the record set has the new values.
+func (c *Cutover) verify(p Provider) error {
+	current, err := p.List()
+	if err != nil {
+		return err
+	}
+	expected := &route53.ResourceRecordSet{
+		Name:            aws.String(c.Name),
+		Type:            aws.String(c.Type),
+		TTL:             aws.Int64(c.LowTTL),
+		ResourceRecords: NewResourceRecordList(c.Values),
+	}
+	rrs := findRecordSet(current, c.Name, c.Type)
+	if rrs == nil || !recordSetEqual(rrs, expected) {
+		return fmt.Errorf(
+			"record %s %s doesn't have the new values yet",
+			c.Name,
+			c.Type,
+		)
+	}
+	return nil
+}
+
+// findRecordSet returns the record set with the simple routing policy and
+// name and type in list, or nil if there is none.
+func findRecordSet(
+	list []*route53.ResourceRecordSet,
+	name string,
+	typ string,
+) *route53.ResourceRecordSet {
+	return findRoutedRecordSet(list, name, typ, "")
+}
+
+// findRoutedRecordSet returns the record set with name, type and set
+// identifier in list, or nil if there is none. An empty set identifier
+// stands for the simple routing policy.
+func findRoutedRecordSet(
+	list []*route53.ResourceRecordSet,
+	name string,
+	typ string,
+	setIdentifier string,
+) *route53.ResourceRecordSet {
+	rrs := &route53.ResourceRecordSet{
+		Name: aws.String(Fqdn(name)),
+		Type: aws.String(strings.ToUpper(typ)),
+	}
+	if setIdentifier != "" {
+		rrs.SetIdentifier = aws.String(setIdentifier)
+	}
+	key := recordSetKey(rrs)
+	for _, rrs := range list {
+		if recordSetKey(rrs) == key {
+			return rrs
+		}
+	}
+	return nil
+}
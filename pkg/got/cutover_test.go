@@ -0,0 +1,161 @@
+package got
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// memoryProvider is a Provider keeping the record sets in memory.
+type memoryProvider struct {
+	records []*route53.ResourceRecordSet
+	applied int
+	waited  []string
+	fail    bool
+}
+
+func (p *memoryProvider) List() ([]*route53.ResourceRecordSet, error) {
+	return p.records, nil
+}
+
+func (p *memoryProvider) Apply(changes []*route53.Change) ([]string, error) {
+	if p.fail {
+		return nil, fmt.Errorf("apply failed")
+	}
+	for _, change := range changes {
+		kept := []*route53.ResourceRecordSet{}
+		for _, rrs := range p.records {
+			if recordSetKey(rrs) != recordSetKey(change.ResourceRecordSet) {
+				kept = append(kept, rrs)
+			}
+		}
+		if *change.Action != "DELETE" {
+			kept = append(kept, change.ResourceRecordSet)
+		}
+		p.records = kept
+	}
+	p.applied++
+	return []string{fmt.Sprintf("C%d", p.applied)}, nil
+}
+
+func (p *memoryProvider) Status(
+	ctx context.Context,
+	id string,
+) (bool, string, error) {
+	p.waited = append(p.waited, id)
+	return true, "applied", nil
+}
+
+func TestCutover(t *testing.T) {
+	var slept time.Duration
+	sleep := cutoverSleep
+	cutoverSleep = func(ctx context.Context, d time.Duration) error {
+		slept = d
+		return nil
+	}
+	defer func() { cutoverSleep = sleep }()
+
+	p := &memoryProvider{
+		records: []*route53.ResourceRecordSet{
+			newRecordSet("api.example.com.", "A", 3600, "10.0.0.1"),
+		},
+	}
+	c, err := NewCutover("example.com", "api.example.com", "A", []string{"10.0.0.2"}, 60, p.records)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "cutover.json")
+	steps := []string{}
+	err = c.Run(context.Background(), p, NewWaiter(), func(c *Cutover) error {
+		steps = append(steps, c.Step)
+		return c.Save(path)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := []string{
+		CutoverExpireTTL,
+		CutoverSwap,
+		CutoverVerify,
+		CutoverRestoreTTL,
+		CutoverDone,
+	}
+	if fmt.Sprint(steps) != fmt.Sprint(expected) {
+		t.Errorf("Expected steps %v, received %v", expected, steps)
+	}
+	if slept < 59*time.Minute || slept > time.Hour {
+		t.Errorf("Expected to wait for the old TTL, waited %s", slept)
+	}
+	if len(p.waited) != 3 {
+		t.Errorf("Expected to wait for 3 changes, waited for %v", p.waited)
+	}
+	rrs := p.records[0]
+	if *rrs.TTL != 3600 || *rrs.ResourceRecords[0].Value != "10.0.0.2" {
+		t.Errorf("Unexpected final record set %v", rrs)
+	}
+	saved, err := LoadCutover(path)
+	if err != nil || saved.Step != CutoverDone {
+		t.Errorf("Unexpected saved state %v, %v", saved, err)
+	}
+}
+
+func TestCutoverResume(t *testing.T) {
+	sleep := cutoverSleep
+	cutoverSleep = func(context.Context, time.Duration) error { return nil }
+	defer func() { cutoverSleep = sleep }()
+
+	p := &memoryProvider{
+		records: []*route53.ResourceRecordSet{
+			newRecordSet("api.example.com.", "A", 60, "10.0.0.1"),
+		},
+		fail: true,
+	}
+	c := &Cutover{
+		Zone:        "example.com",
+		Name:        "api.example.com.",
+		Type:        "A",
+		Values:      []string{"10.0.0.2"},
+		OldValues:   []string{"10.0.0.1"},
+		OriginalTTL: 300,
+		LowTTL:      60,
+		Step:        CutoverSwap,
+	}
+	save := func(*Cutover) error { return nil }
+	if err := c.Run(context.Background(), p, NewWaiter(), save); err == nil || c.Step != CutoverSwap {
+		t.Fatalf("Expected failure at swap, received %v at %s", err, c.Step)
+	}
+	p.fail = false
+	if err := c.Run(context.Background(), p, NewWaiter(), save); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if p.applied != 2 {
+		t.Errorf("Expected swap and TTL restore only, applied %d", p.applied)
+	}
+}
+
+func TestNewCutoverErrors(t *testing.T) {
+	if _, err := NewCutover("example.com", "missing.example.com", "A", nil, 60, routingList); err == nil ||
+		err.Error() != "record missing.example.com A not found" {
+		t.Errorf("Expected missing record error, received %v", err)
+	}
+}
+
+func TestCutoverHasValues(t *testing.T) {
+	c := &Cutover{Type: "A", Values: []string{"10.0.0.2", "10.0.0.3"}}
+	if !c.HasValues([]string{"10.0.0.3", "10.0.0.2"}) {
+		t.Errorf("Expected values in another order to match")
+	}
+	for _, values := range [][]string{
+		{"10.0.0.2"},
+		{"10.0.0.2", "10.0.0.4"},
+		{"10.0.0.2", "10.0.0.3", "10.0.0.4"},
+	} {
+		if c.HasValues(values) {
+			t.Errorf("Unexpected match of %v", values)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package got
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// recordSetKey identifies a record set by its name, type and set
+// identifier.
+func recordSetKey(rrs *route53.ResourceRecordSet) string {
+	key := strings.ToLower(zoneFileName(*rrs.Name)) + " " + *rrs.Type
+	if rrs.SetIdentifier != nil {
+		key += " " + *rrs.SetIdentifier
+	}
+	return key
+}
+
+// DiffRecordSets returns the changes needed for the current record sets to
+// match the desired ones. Record sets only present in current are deleted
+// when prune is true, and left untouched otherwise. Deletions go first in
+// the list, so names can change their type in a single batch.
+func DiffRecordSets(
+	current []*route53.ResourceRecordSet,
+	desired []*route53.ResourceRecordSet,
+	prune bool,
+) (changes []*route53.Change) {
+	existing := map[string]*route53.ResourceRecordSet{}
+	for _, rrs := range current {
+		existing[recordSetKey(rrs)] = rrs
+	}
+	wanted := map[string]bool{}
+	creates := []*route53.Change{}
+	upserts := []*route53.Change{}
+	for _, rrs := range desired {
+		key := recordSetKey(rrs)
+		wanted[key] = true
+		old, ok := existing[key]
+		switch {
+		case !ok:
+			creates = append(creates, &route53.Change{
+				Action:            aws.String("CREATE"),
+				ResourceRecordSet: rrs,
+			})
+		case !recordSetEqual(old, rrs):
+			upserts = append(upserts, &route53.Change{
+				Action:            aws.String("UPSERT"),
+				ResourceRecordSet: rrs,
+			})
+		}
+	}
+	if prune {
+		for _, rrs := range current {
+			if !wanted[recordSetKey(rrs)] {
+				changes = append(changes, &route53.Change{
+					Action:            aws.String("DELETE"),
+					ResourceRecordSet: rrs,
+				})
+			}
+		}
+	}
+	changes = append(changes, creates...)
+	changes = append(changes, upserts...)
+	return
+}
+
+// WriteChanges writes a line per change with its action, name, type, TTL
+// and values.
+func WriteChanges(w io.Writer, changes []*route53.Change) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, change := range changes {
+		rrs := change.ResourceRecordSet
+		if _, err := fmt.Fprintf(
+			tw,
+			"%s\t%s\t%s\t%s\t%s\n",
+			*change.Action,
+			*rrs.Name,
+			*rrs.Type,
+			formatTTL(rrs),
+			strings.Join(formatValues(rrs), ", "),
+		); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// formatTTL returns the TTL of a record set, or - if it has none.
+func formatTTL(rrs *route53.ResourceRecordSet) string {
+	if rrs.TTL == nil {
+		return "-"
+	}
+	return fmt.Sprint(*rrs.TTL)
+}
+
+// formatValues returns the values of a record set, or its alias target.
+func formatValues(rrs *route53.ResourceRecordSet) (values []string) {
+	if rrs.AliasTarget != nil {
+		return []string{"ALIAS " + aws.StringValue(rrs.AliasTarget.DNSName)}
+	}
+	for _, rr := range rrs.ResourceRecords {
+		values = append(values, *rr.Value)
+	}
+	return
+}
+
+// RemoveZoneAuthority returns the records without the SOA and the NS
+// records at the apex of zone, which are managed by the DNS provider and
+// must be left alone when synchronizing zones.
+func RemoveZoneAuthority(
+	records []*route53.ResourceRecordSet,
+	zone string,
+) (result []*route53.ResourceRecordSet) {
+	apex := strings.ToLower(zoneFileName(Fqdn(zone)))
+	for _, rrs := range records {
+		if *rrs.Type == "SOA" {
+			continue
+		}
+		if *rrs.Type == "NS" &&
+			strings.ToLower(zoneFileName(*rrs.Name)) == apex {
+			continue
+		}
+		result = append(result, rrs)
+	}
+	return
+}
+
+// recordSetEqual returns true if both record sets hold the same data.
+func recordSetEqual(a, b *route53.ResourceRecordSet) bool {
+	if aws.Int64Value(a.TTL) != aws.Int64Value(b.TTL) || !routingEqual(a, b) {
+		return false
+	}
+	if (a.AliasTarget == nil) != (b.AliasTarget == nil) {
+		return false
+	}
+	if a.AliasTarget != nil &&
+		(!strings.EqualFold(
+			Fqdn(aws.StringValue(a.AliasTarget.DNSName)),
+			Fqdn(aws.StringValue(b.AliasTarget.DNSName)),
+		) ||
+			aws.StringValue(a.AliasTarget.HostedZoneId) !=
+				aws.StringValue(b.AliasTarget.HostedZoneId) ||
+			aws.BoolValue(a.AliasTarget.EvaluateTargetHealth) !=
+				aws.BoolValue(b.AliasTarget.EvaluateTargetHealth)) {
+		return false
+	}
+	return equalValues(recordSetValues(a), recordSetValues(b))
+}
+
+// equalValues returns whether both lists of values are the same.
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// recordSetValues returns the normalized values of a record set, sorted.
+func recordSetValues(rrs *route53.ResourceRecordSet) (values []string) {
+	for _, rr := range rrs.ResourceRecords {
+		values = append(values, normalizeValue(*rrs.Type, *rr.Value))
+	}
+	sort.Strings(values)
+	return
+}
+
+// normalizeValue returns value in a form that can be compared regardless of
+// quoting or capitalization where it isn't significant.
+func normalizeValue(typ, value string) string {
+	value = strings.TrimSpace(value)
+	switch typ {
+	case "TXT", "SPF":
+		return convertTXT(value, 8, 8)
+	case "CNAME", "NS", "PTR", "MX", "SRV":
+		return strings.ToLower(value)
+	}
+	return value
+}
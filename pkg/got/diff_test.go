@@ -0,0 +1,89 @@
+package got
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func newRecordSet(
+	name string,
+	typ string,
+	ttl int64,
+	values ...string,
+) *route53.ResourceRecordSet {
+	return &route53.ResourceRecordSet{
+		Name:            aws.String(name),
+		Type:            aws.String(typ),
+		TTL:             aws.Int64(ttl),
+		ResourceRecords: NewResourceRecordList(values),
+	}
+}
+
+var diffCurrent = []*route53.ResourceRecordSet{
+	newRecordSet("example.com.", "SOA", 900, "ns1. hostmaster. 1 2 3 4 5"),
+	newRecordSet("example.com.", "NS", 172800, "ns1.example.com."),
+	newRecordSet("same.example.com.", "A", 300, "10.0.0.1", "10.0.0.2"),
+	newRecordSet("ttl.example.com.", "A", 300, "10.0.0.3"),
+	newRecordSet("gone.example.com.", "A", 300, "10.0.0.4"),
+	newRecordSet("\\052.example.com.", "CNAME", 300, "www.example.com."),
+	newRecordSet("txt.example.com.", "TXT", 300, "\"caf\\351\""),
+}
+
+var diffDesired = []*route53.ResourceRecordSet{
+	newRecordSet("same.example.com.", "A", 300, "10.0.0.2", "10.0.0.1"),
+	newRecordSet("ttl.example.com.", "A", 60, "10.0.0.3"),
+	newRecordSet("new.example.com.", "A", 300, "10.0.0.5"),
+	newRecordSet("*.example.com.", "CNAME", 300, "WWW.example.com."),
+	newRecordSet("txt.example.com.", "TXT", 300, "caf\\351"),
+}
+
+var diffTests = []struct {
+	name     string
+	prune    bool
+	expected []string
+}{
+	{
+		"without prune",
+		false,
+		[]string{
+			"CREATE new.example.com. A",
+			"UPSERT ttl.example.com. A",
+		},
+	},
+	{
+		"with prune",
+		true,
+		[]string{
+			"DELETE gone.example.com. A",
+			"CREATE new.example.com. A",
+			"UPSERT ttl.example.com. A",
+		},
+	},
+}
+
+func TestDiffRecordSets(t *testing.T) {
+	current := RemoveZoneAuthority(diffCurrent, "example.com")
+	for _, tt := range diffTests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := DiffRecordSets(current, diffDesired, tt.prune)
+			if len(changes) != len(tt.expected) {
+				t.Fatalf(
+					"Expected %d changes, received %d: %v",
+					len(tt.expected),
+					len(changes),
+					changes,
+				)
+			}
+			for i, change := range changes {
+				out := *change.Action + " " +
+					*change.ResourceRecordSet.Name + " " +
+					*change.ResourceRecordSet.Type
+				if out != tt.expected[i] {
+					t.Errorf("Expected %s, received %s", tt.expected[i], out)
+				}
+			}
+		})
+	}
+}
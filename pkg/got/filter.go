@@ -0,0 +1,449 @@
+package got
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// Filter is a parsed filter expression selecting record sets, such as
+//
+//	type in (A, CNAME) and name =~ "^api-" and ttl > 300
+//
+// Comparisons have a field, an operator and a value, and can be combined
+// with and, or, not and parentheses. Fields are:
+//
+//	name            Compared with = and != as a shell pattern.
+//	type            Compared case insensitively.
+//	ttl             Compared as a number, also with <, <=, > and >=.
+//	value           Any of the values of the record set, or its alias target.
+//	set_identifier  Empty for record sets without routing policy.
+//
+// Every field can be compared with a regular expression using =~ and !~,
+// and with a list of values using in and not in.
+type Filter struct {
+	expression string
+	root       filterNode
+}
+
+// ParseFilter parses the filter expression.
+func ParseFilter(expression string) (f *Filter, err error) {
+	tokens, err := lexFilter(expression)
+	if err != nil {
+		return
+	}
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return
+	}
+	if !p.done() {
+		err = fmt.Errorf("unexpected %s in filter", p.peek())
+		return
+	}
+	f = &Filter{expression: expression, root: root}
+	return
+}
+
+// String returns the expression the filter was parsed from.
+func (f *Filter) String() string {
+	return f.expression
+}
+
+// Match returns whether the record set passes the filter.
+func (f *Filter) Match(rrs *route53.ResourceRecordSet) bool {
+	return f.root.match(rrs)
+}
+
+// Select returns the record sets in list passing the filter.
+func (f *Filter) Select(
+	list []*route53.ResourceRecordSet,
+) (result []*route53.ResourceRecordSet) {
+	for _, rrs := range list {
+		if f.Match(rrs) {
+			result = append(result, rrs)
+		}
+	}
+	return
+}
+
+// QuoteFilterValue returns s quoted to be used as a value in a filter
+// expression.
+func QuoteFilterValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+type filterNode interface {
+	match(rrs *route53.ResourceRecordSet) bool
+}
+
+type andNode struct {
+	left, right filterNode
+}
+
+func (n *andNode) match(rrs *route53.ResourceRecordSet) bool {
+	return n.left.match(rrs) && n.right.match(rrs)
+}
+
+type orNode struct {
+	left, right filterNode
+}
+
+func (n *orNode) match(rrs *route53.ResourceRecordSet) bool {
+	return n.left.match(rrs) || n.right.match(rrs)
+}
+
+type notNode struct {
+	node filterNode
+}
+
+func (n *notNode) match(rrs *route53.ResourceRecordSet) bool {
+	return !n.node.match(rrs)
+}
+
+// comparison is a filterNode comparing a field of the record set with a
+// list of values, or a regular expression.
+type comparison struct {
+	field    string
+	operator string
+	values   []string
+	re       *regexp.Regexp
+}
+
+func (c *comparison) match(rrs *route53.ResourceRecordSet) bool {
+	switch c.operator {
+	case "=", "in":
+		return c.equal(rrs)
+	case "!=", "not in":
+		return !c.equal(rrs)
+	case "=~":
+		return c.matchRegexp(rrs)
+	case "!~":
+		return !c.matchRegexp(rrs)
+	}
+	return TTLFilter(rrs, c.operator+c.values[0]) != nil
+}
+
+// filterPredicates are the predicates comparing fields for equality, other
+// than value.
+var filterPredicates = map[string]func(
+	*route53.ResourceRecordSet,
+	string,
+) *route53.ResourceRecordSet{
+	"name":           NameFilter,
+	"type":           TypeFilter,
+	"ttl":            TTLFilter,
+	"set_identifier": SetIdentifierFilter,
+}
+
+// equal returns whether the field of the record set equals any value.
+func (c *comparison) equal(rrs *route53.ResourceRecordSet) bool {
+	if predicate, found := filterPredicates[c.field]; found {
+		return len(FilterResourceRecords(
+			[]*route53.ResourceRecordSet{rrs},
+			c.values,
+			predicate,
+		)) > 0
+	}
+	for _, value := range c.values {
+		value = normalizeValue(*rrs.Type, value)
+		for _, s := range c.fieldValues(rrs) {
+			if normalizeValue(*rrs.Type, s) == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchRegexp returns whether any of the values of the field of the record
+// set matches the regular expression.
+func (c *comparison) matchRegexp(rrs *route53.ResourceRecordSet) bool {
+	for _, s := range c.fieldValues(rrs) {
+		if c.re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValues returns the values of the field of the record set.
+func (c *comparison) fieldValues(rrs *route53.ResourceRecordSet) []string {
+	switch c.field {
+	case "name":
+		return []string{zoneFileName(*rrs.Name)}
+	case "type":
+		return []string{*rrs.Type}
+	case "ttl":
+		if rrs.TTL == nil {
+			return nil
+		}
+		return []string{strconv.FormatInt(*rrs.TTL, 10)}
+	case "set_identifier":
+		return []string{aws.StringValue(rrs.SetIdentifier)}
+	}
+	values := NewRecord(rrs).Values
+	if rrs.AliasTarget != nil {
+		values = append(values, aws.StringValue(rrs.AliasTarget.DNSName))
+	}
+	return values
+}
+
+var filterFields = map[string]bool{
+	"name":           true,
+	"type":           true,
+	"ttl":            true,
+	"value":          true,
+	"set_identifier": true,
+}
+
+// filterToken is a token of a filter expression. Quoted strings are never
+// keywords or operators.
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func (t filterToken) String() string {
+	if t.quoted {
+		return QuoteFilterValue(t.text)
+	}
+	return t.text
+}
+
+// is returns whether the token is the unquoted keyword or symbol s.
+func (t filterToken) is(s string) bool {
+	return !t.quoted && strings.EqualFold(t.text, s)
+}
+
+var filterOperators = []string{"=~", "!~", "!=", "<=", ">=", "==", "=", "<", ">"}
+
+// lexFilter splits the expression in tokens.
+func lexFilter(expression string) (tokens []filterToken, err error) {
+	s := expression
+	for {
+		s = strings.TrimLeft(s, " \t\n")
+		if s == "" {
+			return
+		}
+		switch {
+		case s[0] == '(' || s[0] == ')' || s[0] == ',':
+			tokens = append(tokens, filterToken{text: s[:1]})
+			s = s[1:]
+			continue
+		case s[0] == '"':
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) &&
+					(s[i+1] == '"' || s[i+1] == '\\') {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				err = fmt.Errorf("unterminated string in filter")
+				return
+			}
+			tokens = append(tokens, filterToken{text: b.String(), quoted: true})
+			s = s[i+1:]
+			continue
+		}
+		operator := ""
+		for _, op := range filterOperators {
+			if strings.HasPrefix(s, op) {
+				operator = op
+				break
+			}
+		}
+		if operator != "" {
+			tokens = append(tokens, filterToken{text: operator})
+			s = s[len(operator):]
+			continue
+		}
+		end := strings.IndexAny(s, " \t\n(),\"=!<>")
+		if end < 0 {
+			end = len(s)
+		}
+		if end == 0 {
+			err = fmt.Errorf("unexpected %c in filter", s[0])
+			return
+		}
+		tokens = append(tokens, filterToken{text: s[:end]})
+		s = s[end:]
+	}
+}
+
+// filterParser builds the tree of a filter expression from its tokens.
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{text: "end of filter"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// expect consumes the symbol s, failing if it's not next.
+func (p *filterParser) expect(s string) error {
+	if t := p.next(); !t.is(s) {
+		return fmt.Errorf("expected %s in filter, found %s", s, t)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (node filterNode, err error) {
+	if node, err = p.parseAnd(); err != nil {
+		return
+	}
+	for !p.done() && p.peek().is("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		node = &orNode{left: node, right: right}
+	}
+	return
+}
+
+func (p *filterParser) parseAnd() (node filterNode, err error) {
+	if node, err = p.parseUnary(); err != nil {
+		return
+	}
+	for !p.done() && p.peek().is("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		node = &andNode{left: node, right: right}
+	}
+	return
+}
+
+func (p *filterParser) parseUnary() (node filterNode, err error) {
+	switch t := p.peek(); {
+	case t.is("not"):
+		p.next()
+		if node, err = p.parseUnary(); err != nil {
+			return
+		}
+		return &notNode{node: node}, nil
+	case t.is("("):
+		p.next()
+		if node, err = p.parseOr(); err != nil {
+			return
+		}
+		return node, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	field := p.next()
+	if field.quoted || !filterFields[strings.ToLower(field.text)] {
+		return nil, fmt.Errorf("unknown field %s in filter", field)
+	}
+	c := &comparison{field: strings.ToLower(field.text)}
+	operator := p.next()
+	switch {
+	case operator.is("in"):
+		c.operator = "in"
+	case operator.is("not"):
+		if err := p.expect("in"); err != nil {
+			return nil, err
+		}
+		c.operator = "not in"
+	case operator.quoted:
+		return nil, fmt.Errorf("expected operator in filter, found %s", operator)
+	default:
+		c.operator = operator.text
+	}
+	var err error
+	switch c.operator {
+	case "in", "not in":
+		c.values, err = p.parseList()
+	case "=", "==", "!=", "=~", "!~", "<", "<=", ">", ">=":
+		c.values, err = p.parseValue()
+	default:
+		err = fmt.Errorf("expected operator in filter, found %s", operator)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch c.operator {
+	case "==":
+		c.operator = "="
+	case "=~", "!~":
+		if c.re, err = regexp.Compile(c.values[0]); err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %s", err)
+		}
+	case "<", "<=", ">", ">=":
+		if c.field != "ttl" {
+			return nil, fmt.Errorf(
+				"%s can't be compared with %s",
+				c.field,
+				c.operator,
+			)
+		}
+	}
+	if c.field == "ttl" && c.re == nil {
+		for _, value := range c.values {
+			if _, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid TTL %s in filter", value)
+			}
+		}
+	}
+	return c, nil
+}
+
+// parseValue parses a single value, which must be quoted if it contains
+// spaces, parentheses, commas or operators.
+func (p *filterParser) parseValue() ([]string, error) {
+	if p.done() {
+		return nil, fmt.Errorf("expected value in filter, found %s", p.peek())
+	}
+	t := p.next()
+	if !t.quoted && strings.ContainsAny(t.text, "(),=!<>") {
+		return nil, fmt.Errorf("expected value in filter, found %s", t)
+	}
+	return []string{t.text}, nil
+}
+
+// parseList parses a parenthesized list of values separated by commas.
+func (p *filterParser) parseList() (values []string, err error) {
+	if err = p.expect("("); err != nil {
+		return
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value...)
+		if t := p.next(); t.is(")") {
+			return values, nil
+		} else if !t.is(",") {
+			return nil, fmt.Errorf("expected , or ) in filter, found %s", t)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package got
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var filterList = []*route53.ResourceRecordSet{
+	newRecordSet("api-1.example.com.", "A", 600, "10.0.0.1"),
+	newRecordSet("api-2.example.com.", "CNAME", 60, "api-1.example.com."),
+	newRecordSet("staging-web.example.com.", "CNAME", 300, "web.example.net."),
+	newRecordSet("staging-db.example.com.", "A", 300, "10.0.1.1"),
+	newRecordSet("txt.example.com.", "TXT", 3600, "\"v=spf1 -all\""),
+	{
+		Name:          aws.String("www.example.com."),
+		Type:          aws.String("A"),
+		SetIdentifier: aws.String("blue"),
+		AliasTarget: &route53.AliasTarget{
+			DNSName:      aws.String("lb.elb.amazonaws.com."),
+			HostedZoneId: aws.String("Z35SXDOTRQ7X7K"),
+		},
+	},
+}
+
+var filterCases = []struct {
+	name       string
+	expression string
+	expected   string
+}{
+	{
+		name:       "Example from the docs",
+		expression: `type in (A,CNAME) and name =~ "^api-" and ttl > 300`,
+		expected:   "api-1.example.com.",
+	},
+	{
+		name:       "All staging CNAMEs",
+		expression: "name = staging-*.example.com and type = cname",
+		expected:   "staging-web.example.com.",
+	},
+	{
+		name:       "Or binds looser than and",
+		expression: "type = TXT or type = A and ttl <= 300",
+		expected:   "staging-db.example.com. txt.example.com.",
+	},
+	{
+		name:       "Parentheses and not",
+		expression: "not (type = A or type = TXT)",
+		expected:   "api-2.example.com. staging-web.example.com.",
+	},
+	{
+		name:       "Not in",
+		expression: "type not in (A, TXT)",
+		expected:   "api-2.example.com. staging-web.example.com.",
+	},
+	{
+		name:       "Values and alias targets",
+		expression: `value = 10.0.0.1 or value =~ "elb\.amazonaws\.com\.$"`,
+		expected:   "api-1.example.com. www.example.com.",
+	},
+	{
+		name:       "Quoted TXT value",
+		expression: `value = "\"v=spf1 -all\""`,
+		expected:   "txt.example.com.",
+	},
+	{
+		name:       "Set identifier",
+		expression: "set_identifier = blue",
+		expected:   "www.example.com.",
+	},
+	{
+		name:       "Aliases have no TTL",
+		expression: "ttl != 300 and ttl !~ ^3",
+		expected:   "api-1.example.com. api-2.example.com. www.example.com.",
+	},
+	{
+		name:       "Exact quoted name",
+		expression: `name == "API-2.example.com"`,
+		expected:   "api-2.example.com.",
+	},
+}
+
+func TestFilter(t *testing.T) {
+	for _, tc := range filterCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseFilter(tc.expression)
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			names := []string{}
+			for _, rrs := range f.Select(filterList) {
+				names = append(names, *rrs.Name)
+			}
+			if strings.Join(names, " ") != tc.expected {
+				t.Errorf("Expected %s, received %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+var filterErrorCases = []struct {
+	expression string
+	expected   string
+}{
+	{"", "unknown field end of filter in filter"},
+	{"owner = foo", "unknown field owner in filter"},
+	{"name < foo", "name can't be compared with <"},
+	{"ttl = abc", "invalid TTL abc in filter"},
+	{"name =~ \"(\"", "invalid regular expression: error parsing regexp: missing closing ): `(`"},
+	{"type in (A, CNAME", "expected , or ) in filter, found end of filter"},
+	{"type = A and", "unknown field end of filter in filter"},
+	{"type = A )", "unexpected ) in filter"},
+	{"type A", "expected operator in filter, found A"},
+	{"name = \"foo", "unterminated string in filter"},
+	{"(type = A", "expected ) in filter, found end of filter"},
+	{"type =", "expected value in filter, found end of filter"},
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, tc := range filterErrorCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := ParseFilter(tc.expression)
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("Expected error %q, received %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestQuoteFilterValue(t *testing.T) {
+	name := `\052.example.com. "quoted"`
+	f, err := ParseFilter("name = " + QuoteFilterValue(name))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	c := f.root.(*comparison)
+	if c.values[0] != name {
+		t.Errorf("Expected %s, received %s", name, c.values[0])
+	}
+}
@@ -2,13 +2,11 @@ package got
 
 import (
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/aws/aws-sdk-go/service/route53/route53iface"
-
-	"github.com/poka-yoke/spaceflight/internal/http"
 )
 
 // GetResourceRecordSet returns a slice containing all responses for specified
@@ -33,6 +31,7 @@ func GetResourceRecordSet(
 		if *resp.IsTruncated {
 			params.StartRecordName = resp.NextRecordName
 			params.StartRecordType = resp.NextRecordType
+			params.StartRecordIdentifier = resp.NextRecordIdentifier
 		}
 		respIsTruncated = *resp.IsTruncated
 		resourceRecordSet = append(resourceRecordSet, resp.ResourceRecordSets...)
@@ -54,12 +53,15 @@ func NewResourceRecordList(values []string) (ret []*route53.ResourceRecord) {
 }
 
 // UpsertChangeList generates a list of changes for UPSERT the records in list
-// according with ttl, name, and type
+// according with ttl, name, and type. The routing, if not nil, sets the
+// routing policy of the record set, or makes it an alias, in which case
+// list and ttl are ignored.
 func UpsertChangeList(
 	list []*route53.ResourceRecord,
 	ttl int64,
 	name string,
 	typ string,
+	routing *Routing,
 ) (res []*route53.Change) {
 	val := &route53.ResourceRecordSet{
 		ResourceRecords: list,
@@ -67,6 +69,7 @@ func UpsertChangeList(
 		Type:            &typ,
 		Name:            &name,
 	}
+	routing.apply(val)
 	change := &route53.Change{
 		Action:            aws.String("UPSERT"),
 		ResourceRecordSet: val,
@@ -76,96 +79,222 @@ func UpsertChangeList(
 }
 
 // DeleteChangeList generates a list of changes for DELETEing the records in
-// names, according to a common type
+// names, according to a common type and set identifier, which is empty for
+// records using the simple routing policy. It fails if any of the records
+// doesn't exist.
 func DeleteChangeList(
 	names []string,
 	typ string,
+	setIdentifier string,
 	list []*route53.ResourceRecordSet,
-) (res []*route53.Change) {
-	var record *route53.ResourceRecordSet
+) (res []*route53.Change, err error) {
+	missing := []string{}
+	for _, name := range names {
+		record := findRoutedRecordSet(list, name, typ, setIdentifier)
+		if record == nil {
+			missing = append(missing, name)
+			continue
+		}
+		change := &route53.Change{
+			Action:            aws.String("DELETE"),
+			ResourceRecordSet: record,
+		}
+		res = append(res, change)
+	}
+	if len(missing) > 0 {
+		return nil, notFoundError(missing, typ, setIdentifier)
+	}
+	return
+}
+
+// RemoveValuesChangeList generates a list of changes removing values from
+// the records in names, according to a common type and set identifier.
+// Records left with no values are DELETEd, while the rest are UPSERTed with
+// the remaining values. It fails if any of the records doesn't exist, or
+// doesn't have every value.
+func RemoveValuesChangeList(
+	names []string,
+	typ string,
+	setIdentifier string,
+	values []string,
+	list []*route53.ResourceRecordSet,
+) (res []*route53.Change, err error) {
+	missing := []string{}
 	for _, name := range names {
-		for _, i := range list {
-			if *i.Name == name &&
-				*i.Type == typ {
-				record = i
+		record := findRoutedRecordSet(list, name, typ, setIdentifier)
+		if record == nil {
+			missing = append(missing, name)
+			continue
+		}
+		removed := map[string]bool{}
+		for _, value := range values {
+			removed[normalizeValue(*record.Type, value)] = false
+		}
+		remaining := []*route53.ResourceRecord{}
+		for _, rr := range record.ResourceRecords {
+			key := normalizeValue(*record.Type, *rr.Value)
+			if _, ok := removed[key]; ok {
+				removed[key] = true
+				continue
+			}
+			remaining = append(remaining, rr)
+		}
+		for _, value := range values {
+			if !removed[normalizeValue(*record.Type, value)] {
+				err = fmt.Errorf(
+					"record %s %s has no value %s",
+					*record.Name,
+					*record.Type,
+					value,
+				)
+				return nil, err
 			}
 		}
 		change := &route53.Change{
 			Action:            aws.String("DELETE"),
 			ResourceRecordSet: record,
 		}
+		if len(remaining) > 0 {
+			updated := *record
+			updated.ResourceRecords = remaining
+			change = &route53.Change{
+				Action:            aws.String("UPSERT"),
+				ResourceRecordSet: &updated,
+			}
+		}
 		res = append(res, change)
 	}
+	if len(missing) > 0 {
+		return nil, notFoundError(missing, typ, setIdentifier)
+	}
 	return
 }
 
-// WaitForChangeToComplete waits until the ChangeInfo described by the argument is completed.
-func WaitForChangeToComplete(
-	changeInfo *route53.ChangeInfo,
-	svc *route53.Route53,
-) {
-	getChangeInput := &route53.GetChangeInput{Id: changeInfo.Id}
-	req, getChangeOutput := svc.GetChangeRequest(getChangeInput)
+// notFoundError returns the error for the records in names missing from a
+// zone.
+func notFoundError(names []string, typ, setIdentifier string) error {
+	records := "record"
+	if len(names) > 1 {
+		records = "records"
+	}
+	identifier := ""
+	if setIdentifier != "" {
+		identifier = " with set identifier " + setIdentifier
+	}
+	return fmt.Errorf(
+		"%s %s %s%s not found",
+		strings.ToUpper(typ),
+		records,
+		strings.Join(names, ", "),
+		identifier,
+	)
+}
 
-	http.NewLinearBackoff(
-		func() bool {
-			return *getChangeOutput.ChangeInfo.Status ==
-				route53.ChangeStatusInsync
-		},
-		time.Second,
-		30*time.Second,
-	).Do(req)
+// DeleteRecordSetsChangeList generates a list of changes for DELETEing
+// every record set in list
+func DeleteRecordSetsChangeList(
+	list []*route53.ResourceRecordSet,
+) (res []*route53.Change) {
+	for _, r := range list {
+		res = append(res, &route53.Change{
+			Action:            aws.String("DELETE"),
+			ResourceRecordSet: r,
+		})
+	}
+	return
+}
+
+// TTLChangeList generates a list of changes for UPSERTing the records in list
+// with a new ttl, keeping everything else. Alias records have no TTL, so
+// they are skipped.
+func TTLChangeList(
+	list []*route53.ResourceRecordSet,
+	ttl int64,
+) (res []*route53.Change) {
+	for _, r := range list {
+		if r.AliasTarget != nil {
+			continue
+		}
+		partialChangeSlice := UpsertChangeList(
+			r.ResourceRecords,
+			ttl,
+			*r.Name,
+			*r.Type,
+			RoutingOf(r),
+		)
+		res = append(res, partialChangeSlice...)
+	}
+	return
 }
 
 // UpsertResourceRecordSetTTL performs the request to change the TTL of the list
-// of records.
+// of records, returning the ChangeInfo of every batch sent.
 func UpsertResourceRecordSetTTL(
 	list []*route53.ResourceRecordSet,
 	ttl int64,
 	zoneID *string,
 	svc route53iface.Route53API,
 ) (
-	changeResponse *route53.ChangeResourceRecordSetsOutput,
+	changeInfos []*route53.ChangeInfo,
 	err error,
 ) {
 	if len(list) <= 0 {
 		err = fmt.Errorf("no records to process")
 		return
 	}
-	changeSlice := []*route53.Change{}
-	for _, r := range list {
-		partialChangeSlice := UpsertChangeList(r.ResourceRecords, ttl, *r.Name, *r.Type)
-		changeSlice = append(changeSlice, partialChangeSlice...)
-	}
-
-	changeResponse, err = ApplyChanges(changeSlice, zoneID, svc)
+	changeInfos, err = ApplyChanges(TTLChangeList(list, ttl), zoneID, svc)
 	return
 }
 
-// ApplyChanges performs the request to change the list
-// of records.
+// ApplyChanges performs the requests to change the list of records. Changes
+// are split in as many batches as needed to fit in the Route53 limits, and
+// sent in order. It returns the ChangeInfo of every batch sent, which are
+// all of them unless there is an error.
 func ApplyChanges(
 	changes []*route53.Change,
 	zoneID *string,
 	svc route53iface.Route53API,
 ) (
-	changeResponse *route53.ChangeResourceRecordSetsOutput,
+	changeInfos []*route53.ChangeInfo,
 	err error,
 ) {
 	if len(changes) <= 0 {
 		err = fmt.Errorf("no records to process")
 		return
 	}
-	// Create batch with all jobs
-	changeBatch := &route53.ChangeBatch{
-		Changes: changes,
+	batches := SplitChanges(changes)
+	for _, batch := range batches {
+		changeBatch := &route53.ChangeBatch{
+			Changes: batch,
+		}
+		if err = changeBatch.Validate(); err != nil {
+			return
+		}
 	}
-	if err = changeBatch.Validate(); err != nil {
-		return
+	for _, batch := range batches {
+		var changeResponse *route53.ChangeResourceRecordSetsOutput
+		changeResponse, err = applyBatch(batch, zoneID, svc)
+		if err != nil {
+			return
+		}
+		changeInfos = append(changeInfos, changeResponse.ChangeInfo)
 	}
+	return
+}
 
+// applyBatch performs the request to change the records in a single batch.
+func applyBatch(
+	changes []*route53.Change,
+	zoneID *string,
+	svc route53iface.Route53API,
+) (
+	changeResponse *route53.ChangeResourceRecordSetsOutput,
+	err error,
+) {
 	changeRRSInput := &route53.ChangeResourceRecordSetsInput{
-		ChangeBatch:  changeBatch,
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: changes,
+		},
 		HostedZoneId: zoneID,
 	}
 
@@ -178,7 +307,7 @@ func ApplyChanges(
 }
 
 // FilterResourceRecords returns a slice containing only the entries that
-// pass the check performed by the function argument
+// pass the check performed by the function argument for any of the filters
 func FilterResourceRecords(
 	l []*route53.ResourceRecordSet,
 	f []string,
@@ -190,6 +319,7 @@ func FilterResourceRecords(
 			res := p(elem, filter)
 			if res != nil {
 				result = append(result, res)
+				break
 			}
 		}
 	}
@@ -197,20 +327,13 @@ func FilterResourceRecords(
 }
 
 // GetZoneID returns a string containing the ZoneID for use in further API
-// actions
+// actions. The zone must be named exactly zoneName, and be the only one with
+// that name.
 func GetZoneID(zoneName string, svc route53iface.Route53API) (zoneID string, err error) {
-	params := &route53.ListHostedZonesByNameInput{
-		DNSName:  aws.String(zoneName),
-		MaxItems: aws.String("100"),
-	}
-	resp, err := svc.ListHostedZonesByName(params)
+	zone, err := FindHostedZone(zoneName, nil, svc)
 	if err != nil {
 		return
 	}
-	if len(resp.HostedZones) == 0 {
-		err = fmt.Errorf("no results for zone %s. Exiting", zoneName)
-		return
-	}
-	zoneID = *resp.HostedZones[0].Id
+	zoneID = *zone.Id
 	return
 }
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/aws/aws-sdk-go/service/route53/route53iface"
 )
@@ -45,6 +47,7 @@ var hostedZone = route53.HostedZone{
 // Define a mock struct to be used in unit tests.
 type mockRoute53Client struct {
 	route53iface.Route53API
+	changes []*route53.Change
 }
 
 func (m *mockRoute53Client) ListResourceRecordSets(
@@ -74,6 +77,7 @@ func (m *mockRoute53Client) ListHostedZonesByName(
 func (m *mockRoute53Client) ChangeResourceRecordSets(
 	params *route53.ChangeResourceRecordSetsInput,
 ) (out *route53.ChangeResourceRecordSetsOutput, err error) {
+	m.changes = append(m.changes, params.ChangeBatch.Changes...)
 	out = &route53.ChangeResourceRecordSetsOutput{
 		ChangeInfo: &route53.ChangeInfo{
 			Id:     params.HostedZoneId,
@@ -84,6 +88,20 @@ func (m *mockRoute53Client) ChangeResourceRecordSets(
 	return
 }
 
+func (m *mockRoute53Client) GetChangeWithContext(
+	ctx aws.Context,
+	params *route53.GetChangeInput,
+	opts ...request.Option,
+) (out *route53.GetChangeOutput, err error) {
+	out = &route53.GetChangeOutput{
+		ChangeInfo: &route53.ChangeInfo{
+			Id:     params.Id,
+			Status: pstr(route53.ChangeStatusInsync),
+		},
+	}
+	return
+}
+
 var rrstest = []struct {
 	rrsl       []*route53.ResourceRecordSet
 	typeFilter []string
@@ -276,7 +294,7 @@ func TestNewResourceRecordList(t *testing.T) {
 		t.Run(strings.Join(tt.in, ";"), func(t *testing.T) {
 			out := NewResourceRecordList(tt.in)
 			if len(tt.in) != len(out) {
-				t.Error(
+				t.Errorf(
 					"Erroneous amount of responses."+
 						" Expected %d, received %d.",
 					len(tt.in),
@@ -285,11 +303,11 @@ func TestNewResourceRecordList(t *testing.T) {
 			}
 			for i, v := range out {
 				if *v.Value != *tt.out[i].Value {
-					t.Error(
+					t.Errorf(
 						"Erroneous response."+
 							" Expected %s, received %s.",
 						*tt.out[i].Value,
-						*v,
+						*v.Value,
 					)
 				}
 			}
@@ -351,10 +369,10 @@ func TestApplyChanges(t *testing.T) {
 				&tt.input.zoneid,
 				mockSvc,
 			)
-			if out == nil {
-				t.Error("Returned nil")
-			} else if *out.ChangeInfo.Id != *tt.output.out.ChangeInfo.Id ||
-				*out.ChangeInfo.Status != *tt.output.out.ChangeInfo.Status ||
+			if len(out) != 1 {
+				t.Errorf("Expected a single batch, received %d", len(out))
+			} else if *out[0].Id != *tt.output.out.ChangeInfo.Id ||
+				*out[0].Status != *tt.output.out.ChangeInfo.Status ||
 				err != tt.output.err {
 				t.Error("Unexpected outcome")
 			}
@@ -366,33 +384,158 @@ var dcltest = []struct {
 	names  []string
 	typ    string
 	result []*route53.ResourceRecordSet
+	err    string
 }{
 	{
-		typ: "CNAME",
+		typ: "A",
 		names: []string{
 			"one.example.com.",
 		},
 		result: []*route53.ResourceRecordSet{ResourceRecordSetList[0]},
 	},
+	{
+		typ: "aaaa",
+		names: []string{
+			"two.example.com",
+		},
+		result: []*route53.ResourceRecordSet{ResourceRecordSetList[1]},
+	},
+	{
+		typ: "CNAME",
+		names: []string{
+			"one.example.com.",
+		},
+		err: "CNAME record one.example.com. not found",
+	},
+	{
+		typ: "A",
+		names: []string{
+			"one.example.com.",
+			"three.example.com.",
+			"four.example.com.",
+		},
+		err: "A records three.example.com., four.example.com. not found",
+	},
 }
 
 func TestDeleteChangeList(t *testing.T) {
 	for _, tt := range dcltest {
-		res := DeleteChangeList(tt.names, tt.typ, ResourceRecordSetList)
-		if len(res) != len(tt.names) {
+		res, err := DeleteChangeList(tt.names, tt.typ, "", ResourceRecordSetList)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err || res != nil {
+				t.Errorf("Expected error %q, got %v and %v", tt.err, res, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if len(res) != len(tt.result) {
 			t.Errorf(
 				"Unexpected length of results, expected %d and got %d\n",
-				len(tt.names),
+				len(tt.result),
 				len(res),
 			)
 		}
-		for _, change := range res {
+		for i, change := range res {
 			if *change.Action != "DELETE" {
 				t.Errorf(
 					"Expected DELETE action, got %s\n",
 					*change.Action,
 				)
 			}
+			if change.ResourceRecordSet != tt.result[i] {
+				t.Errorf(
+					"Expected deletion of %v, got %v\n",
+					tt.result[i],
+					change.ResourceRecordSet,
+				)
+			}
+		}
+	}
+}
+
+func TestRemoveValuesChangeList(t *testing.T) {
+	list := []*route53.ResourceRecordSet{
+		newRecordSet("www.example.com.", "A", 60, "10.0.0.1", "10.0.0.2", "10.0.0.3"),
+		newRecordSet("mail.example.com.", "MX", 300, "10 mx.example.com."),
+	}
+	tcs := []struct {
+		name     string
+		names    []string
+		typ      string
+		values   []string
+		expected string
+		err      string
+	}{
+		{
+			name:     "Some values",
+			names:    []string{"www.example.com."},
+			typ:      "A",
+			values:   []string{"10.0.0.1", "10.0.0.3"},
+			expected: "UPSERT www.example.com. A 60 10.0.0.2\n",
+		},
+		{
+			name:     "Every value",
+			names:    []string{"mail.example.com"},
+			typ:      "MX",
+			values:   []string{"10 MX.example.com."},
+			expected: "DELETE mail.example.com. MX 300 10 mx.example.com.\n",
+		},
+		{
+			name:   "Missing value",
+			names:  []string{"www.example.com."},
+			typ:    "A",
+			values: []string{"10.0.0.4"},
+			err:    "record www.example.com. A has no value 10.0.0.4",
+		},
+		{
+			name:   "Missing record",
+			names:  []string{"api.example.com."},
+			typ:    "A",
+			values: []string{"10.0.0.1"},
+			err:    "A record api.example.com. not found",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := RemoveValuesChangeList(tc.names, tc.typ, "", tc.values, list)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error %q, received %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			buf := &strings.Builder{}
+			if err = WriteChanges(buf, res); err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("Expected %q, received %q", tc.expected, buf.String())
+			}
+		})
+	}
+	if len(list[0].ResourceRecords) != 3 {
+		t.Errorf("Current record set modified %v", list[0])
+	}
+}
+
+func TestDeleteRecordSetsChangeList(t *testing.T) {
+	res := DeleteRecordSetsChangeList(ResourceRecordSetList)
+	if len(res) != len(ResourceRecordSetList) {
+		t.Errorf(
+			"Unexpected length of results, expected %d and got %d\n",
+			len(ResourceRecordSetList),
+			len(res),
+		)
+	}
+	for i, change := range res {
+		if *change.Action != "DELETE" ||
+			change.ResourceRecordSet != ResourceRecordSetList[i] {
+			t.Errorf("Unexpected change %v\n", change)
 		}
 	}
 }
@@ -0,0 +1,329 @@
+package got
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// healthCheckTagsPerCall is the most health checks whose tags can be listed
+// in a single call.
+const healthCheckTagsPerCall = 10
+
+// HealthCheckSpec describes a Route53 health check of an endpoint over
+// HTTP, HTTPS or TCP.
+type HealthCheckSpec struct {
+	// Name is set as the Name tag of the health check, the name shown in
+	// the console.
+	Name string
+	// Type is HTTP, HTTPS or TCP. HTTP and HTTPS checks with a SearchString
+	// become HTTP_STR_MATCH and HTTPS_STR_MATCH checks.
+	Type string
+	// IPAddress and FQDN identify the endpoint. At least one of them is
+	// required.
+	IPAddress string
+	FQDN      string
+	// Port defaults to 80 for HTTP and 443 for HTTPS, and is required for
+	// TCP.
+	Port         int64
+	ResourcePath string
+	// SearchString must appear in the first 5120 bytes of the response
+	// body for the endpoint to be healthy.
+	SearchString string
+	// RequestInterval is the seconds between checks, 10 or 30.
+	RequestInterval int64
+	// FailureThreshold is the number of consecutive checks that must fail
+	// or succeed to change the status of the endpoint, from 1 to 10.
+	FailureThreshold int64
+}
+
+// Config returns the HealthCheckConfig described by the spec, failing if
+// it's not valid.
+func (s *HealthCheckSpec) Config() (config *route53.HealthCheckConfig, err error) {
+	typ := strings.ToUpper(s.Type)
+	port := s.Port
+	switch typ {
+	case route53.HealthCheckTypeHttp:
+		if port == 0 {
+			port = 80
+		}
+	case route53.HealthCheckTypeHttps:
+		if port == 0 {
+			port = 443
+		}
+	case route53.HealthCheckTypeTcp:
+		if port == 0 {
+			err = fmt.Errorf("TCP health checks need a port")
+			return
+		}
+		if s.ResourcePath != "" || s.SearchString != "" {
+			err = fmt.Errorf(
+				"TCP health checks can't have a resource path or search string",
+			)
+			return
+		}
+	default:
+		err = fmt.Errorf("unknown health check type %s", s.Type)
+		return
+	}
+	if s.IPAddress == "" && s.FQDN == "" {
+		err = fmt.Errorf("health checks need an IP address or a domain name")
+		return
+	}
+	if len(s.SearchString) > 255 {
+		err = fmt.Errorf("search string longer than 255 characters")
+		return
+	}
+	if s.SearchString != "" {
+		typ += "_STR_MATCH"
+	}
+	interval := s.RequestInterval
+	if interval == 0 {
+		interval = 30
+	}
+	if interval != 10 && interval != 30 {
+		err = fmt.Errorf("request interval must be 10 or 30 seconds")
+		return
+	}
+	threshold := s.FailureThreshold
+	if threshold == 0 {
+		threshold = 3
+	}
+	if threshold < 1 || threshold > 10 {
+		err = fmt.Errorf("failure threshold must be between 1 and 10")
+		return
+	}
+	config = &route53.HealthCheckConfig{
+		Type:             aws.String(typ),
+		Port:             aws.Int64(port),
+		RequestInterval:  aws.Int64(interval),
+		FailureThreshold: aws.Int64(threshold),
+	}
+	if s.IPAddress != "" {
+		config.IPAddress = aws.String(s.IPAddress)
+	}
+	if s.FQDN != "" {
+		config.FullyQualifiedDomainName = aws.String(s.FQDN)
+	}
+	if s.ResourcePath != "" {
+		config.ResourcePath = aws.String(s.ResourcePath)
+	}
+	if s.SearchString != "" {
+		config.SearchString = aws.String(s.SearchString)
+	}
+	if strings.HasPrefix(typ, route53.HealthCheckTypeHttps) && s.FQDN != "" {
+		config.EnableSNI = aws.Bool(true)
+	}
+	return
+}
+
+// CreateHealthCheck creates the health check described by the spec, naming
+// it if the spec has a name. If naming it fails, the health check is
+// deleted, and only returned along with the error if that fails too.
+func CreateHealthCheck(
+	spec *HealthCheckSpec,
+	svc route53iface.Route53API,
+) (check *route53.HealthCheck, err error) {
+	config, err := spec.Config()
+	if err != nil {
+		return
+	}
+	out, err := svc.CreateHealthCheck(&route53.CreateHealthCheckInput{
+		CallerReference: aws.String(
+			fmt.Sprintf("got-%d", time.Now().UnixNano()),
+		),
+		HealthCheckConfig: config,
+	})
+	if err != nil {
+		return
+	}
+	check = out.HealthCheck
+	if spec.Name == "" {
+		return
+	}
+	_, err = svc.ChangeTagsForResource(&route53.ChangeTagsForResourceInput{
+		ResourceId:   check.Id,
+		ResourceType: aws.String(route53.TagResourceTypeHealthcheck),
+		AddTags: []*route53.Tag{
+			{Key: aws.String("Name"), Value: aws.String(spec.Name)},
+		},
+	})
+	if err == nil {
+		return
+	}
+	if derr := DeleteHealthCheck(*check.Id, svc); derr != nil {
+		err = fmt.Errorf(
+			"unable to name health check %s: %s, nor to delete it: %s",
+			*check.Id,
+			err,
+			derr,
+		)
+		return
+	}
+	err = fmt.Errorf(
+		"unable to name health check %s, deleted it: %s",
+		*check.Id,
+		err,
+	)
+	check = nil
+	return
+}
+
+// ListHealthChecks returns every health check in the account.
+func ListHealthChecks(
+	svc route53iface.Route53API,
+) (checks []*route53.HealthCheck, err error) {
+	params := &route53.ListHealthChecksInput{}
+	for {
+		var out *route53.ListHealthChecksOutput
+		out, err = svc.ListHealthChecks(params)
+		if err != nil {
+			return
+		}
+		checks = append(checks, out.HealthChecks...)
+		if !aws.BoolValue(out.IsTruncated) {
+			return
+		}
+		params.Marker = out.NextMarker
+	}
+}
+
+// HealthCheckNames returns the Name tags of the health checks, by ID.
+// Health checks without a name are left out.
+func HealthCheckNames(
+	checks []*route53.HealthCheck,
+	svc route53iface.Route53API,
+) (names map[string]string, err error) {
+	names = map[string]string{}
+	for start := 0; start < len(checks); start += healthCheckTagsPerCall {
+		end := start + healthCheckTagsPerCall
+		if end > len(checks) {
+			end = len(checks)
+		}
+		ids := []*string{}
+		for _, check := range checks[start:end] {
+			ids = append(ids, check.Id)
+		}
+		var out *route53.ListTagsForResourcesOutput
+		out, err = svc.ListTagsForResources(&route53.ListTagsForResourcesInput{
+			ResourceIds:  ids,
+			ResourceType: aws.String(route53.TagResourceTypeHealthcheck),
+		})
+		if err != nil {
+			return
+		}
+		for _, set := range out.ResourceTagSets {
+			for _, tag := range set.Tags {
+				if aws.StringValue(tag.Key) == "Name" {
+					names[*set.ResourceId] = aws.StringValue(tag.Value)
+				}
+			}
+		}
+	}
+	return
+}
+
+// FindHealthCheck returns the health check whose ID or name is ref. It
+// fails if there is none, or if several share the name.
+func FindHealthCheck(
+	ref string,
+	svc route53iface.Route53API,
+) (check *route53.HealthCheck, err error) {
+	checks, err := ListHealthChecks(svc)
+	if err != nil {
+		return
+	}
+	for _, c := range checks {
+		if *c.Id == ref {
+			check = c
+			return
+		}
+	}
+	names, err := HealthCheckNames(checks, svc)
+	if err != nil {
+		return
+	}
+	ids := []string{}
+	for _, c := range checks {
+		if names[*c.Id] == ref {
+			check = c
+			ids = append(ids, *c.Id)
+		}
+	}
+	switch {
+	case len(ids) == 0:
+		err = fmt.Errorf("no health check %s", ref)
+	case len(ids) > 1:
+		check = nil
+		err = fmt.Errorf(
+			"health check %s is ambiguous, it matches %s",
+			ref,
+			strings.Join(ids, ", "),
+		)
+	}
+	return
+}
+
+// DeleteHealthCheck deletes the health check with the ID. Route53 refuses
+// to delete health checks still associated with record sets.
+func DeleteHealthCheck(id string, svc route53iface.Route53API) error {
+	_, err := svc.DeleteHealthCheck(&route53.DeleteHealthCheckInput{
+		HealthCheckId: aws.String(id),
+	})
+	return err
+}
+
+// WriteHealthChecks writes the health checks to w as a table, along with
+// their names.
+func WriteHealthChecks(
+	w io.Writer,
+	checks []*route53.HealthCheck,
+	names map[string]string,
+) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tTYPE\tENDPOINT\tINTERVAL\tTHRESHOLD")
+	for _, check := range checks {
+		config := check.HealthCheckConfig
+		fmt.Fprintf(
+			tw,
+			"%s\t%s\t%s\t%s\t%d\t%d\n",
+			*check.Id,
+			names[*check.Id],
+			aws.StringValue(config.Type),
+			healthCheckEndpoint(config),
+			aws.Int64Value(config.RequestInterval),
+			aws.Int64Value(config.FailureThreshold),
+		)
+	}
+	return tw.Flush()
+}
+
+// healthCheckEndpoint returns the address checked by the health check, as
+// an URL for HTTP and HTTPS checks, or host:port otherwise.
+func healthCheckEndpoint(config *route53.HealthCheckConfig) string {
+	host := aws.StringValue(config.FullyQualifiedDomainName)
+	if host == "" {
+		host = aws.StringValue(config.IPAddress)
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	endpoint := fmt.Sprintf("%s:%d", host, aws.Int64Value(config.Port))
+	typ := aws.StringValue(config.Type)
+	switch {
+	case strings.HasPrefix(typ, route53.HealthCheckTypeHttps):
+		endpoint = "https://" + endpoint + aws.StringValue(config.ResourcePath)
+	case strings.HasPrefix(typ, route53.HealthCheckTypeHttp):
+		endpoint = "http://" + endpoint + aws.StringValue(config.ResourcePath)
+	case typ == route53.HealthCheckTypeTcp:
+	default:
+		endpoint = "-"
+	}
+	return endpoint
+}
@@ -0,0 +1,313 @@
+package got
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// healthChecksRoute53Client is a mock holding health checks, listed in
+// pages of pageSize checks.
+type healthChecksRoute53Client struct {
+	route53iface.Route53API
+	checks   []*route53.HealthCheck
+	names    map[string]string
+	pageSize int
+	// tagErr is returned when tagging health checks, if set.
+	tagErr error
+}
+
+func (m *healthChecksRoute53Client) CreateHealthCheck(
+	params *route53.CreateHealthCheckInput,
+) (*route53.CreateHealthCheckOutput, error) {
+	check := &route53.HealthCheck{
+		Id:                aws.String(fmt.Sprintf("hc-%d", len(m.checks)+1)),
+		CallerReference:   params.CallerReference,
+		HealthCheckConfig: params.HealthCheckConfig,
+	}
+	m.checks = append(m.checks, check)
+	return &route53.CreateHealthCheckOutput{HealthCheck: check}, nil
+}
+
+func (m *healthChecksRoute53Client) ChangeTagsForResource(
+	params *route53.ChangeTagsForResourceInput,
+) (*route53.ChangeTagsForResourceOutput, error) {
+	if m.tagErr != nil {
+		return nil, m.tagErr
+	}
+	for _, tag := range params.AddTags {
+		if *tag.Key == "Name" {
+			m.names[*params.ResourceId] = *tag.Value
+		}
+	}
+	return &route53.ChangeTagsForResourceOutput{}, nil
+}
+
+func (m *healthChecksRoute53Client) ListHealthChecks(
+	params *route53.ListHealthChecksInput,
+) (*route53.ListHealthChecksOutput, error) {
+	start := 0
+	if params.Marker != nil {
+		start, _ = strconv.Atoi(*params.Marker)
+	}
+	end := start + m.pageSize
+	out := &route53.ListHealthChecksOutput{IsTruncated: aws.Bool(end < len(m.checks))}
+	if end >= len(m.checks) {
+		end = len(m.checks)
+	} else {
+		out.NextMarker = aws.String(strconv.Itoa(end))
+	}
+	out.HealthChecks = m.checks[start:end]
+	return out, nil
+}
+
+func (m *healthChecksRoute53Client) ListTagsForResources(
+	params *route53.ListTagsForResourcesInput,
+) (*route53.ListTagsForResourcesOutput, error) {
+	if len(params.ResourceIds) > healthCheckTagsPerCall {
+		return nil, fmt.Errorf("too many resources")
+	}
+	out := &route53.ListTagsForResourcesOutput{}
+	for _, id := range params.ResourceIds {
+		set := &route53.ResourceTagSet{ResourceId: id}
+		if name, ok := m.names[*id]; ok {
+			set.Tags = []*route53.Tag{
+				{Key: aws.String("Name"), Value: aws.String(name)},
+			}
+		}
+		out.ResourceTagSets = append(out.ResourceTagSets, set)
+	}
+	return out, nil
+}
+
+func (m *healthChecksRoute53Client) DeleteHealthCheck(
+	params *route53.DeleteHealthCheckInput,
+) (*route53.DeleteHealthCheckOutput, error) {
+	for i, check := range m.checks {
+		if *check.Id == *params.HealthCheckId {
+			m.checks = append(m.checks[:i], m.checks[i+1:]...)
+			return &route53.DeleteHealthCheckOutput{}, nil
+		}
+	}
+	return nil, fmt.Errorf("no health check %s", *params.HealthCheckId)
+}
+
+func newHealthChecksRoute53Client() *healthChecksRoute53Client {
+	return &healthChecksRoute53Client{
+		names:    map[string]string{},
+		pageSize: 4,
+	}
+}
+
+func TestHealthCheckSpecConfig(t *testing.T) {
+	tcs := []struct {
+		name     string
+		spec     HealthCheckSpec
+		expected *route53.HealthCheckConfig
+		err      string
+	}{
+		{
+			name: "HTTP defaults",
+			spec: HealthCheckSpec{Type: "http", IPAddress: "10.0.0.1"},
+			expected: &route53.HealthCheckConfig{
+				Type:             aws.String("HTTP"),
+				IPAddress:        aws.String("10.0.0.1"),
+				Port:             aws.Int64(80),
+				RequestInterval:  aws.Int64(30),
+				FailureThreshold: aws.Int64(3),
+			},
+		},
+		{
+			name: "HTTPS string matching",
+			spec: HealthCheckSpec{
+				Type:             "HTTPS",
+				FQDN:             "api.example.com",
+				ResourcePath:     "/health",
+				SearchString:     "ok",
+				RequestInterval:  10,
+				FailureThreshold: 2,
+			},
+			expected: &route53.HealthCheckConfig{
+				Type:                     aws.String("HTTPS_STR_MATCH"),
+				FullyQualifiedDomainName: aws.String("api.example.com"),
+				Port:                     aws.Int64(443),
+				ResourcePath:             aws.String("/health"),
+				SearchString:             aws.String("ok"),
+				RequestInterval:          aws.Int64(10),
+				FailureThreshold:         aws.Int64(2),
+				EnableSNI:                aws.Bool(true),
+			},
+		},
+		{
+			name: "TCP",
+			spec: HealthCheckSpec{Type: "TCP", IPAddress: "10.0.0.1", Port: 5432},
+			expected: &route53.HealthCheckConfig{
+				Type:             aws.String("TCP"),
+				IPAddress:        aws.String("10.0.0.1"),
+				Port:             aws.Int64(5432),
+				RequestInterval:  aws.Int64(30),
+				FailureThreshold: aws.Int64(3),
+			},
+		},
+		{
+			name: "TCP without port",
+			spec: HealthCheckSpec{Type: "TCP", IPAddress: "10.0.0.1"},
+			err:  "TCP health checks need a port",
+		},
+		{
+			name: "TCP with search string",
+			spec: HealthCheckSpec{
+				Type:         "TCP",
+				IPAddress:    "10.0.0.1",
+				Port:         80,
+				SearchString: "ok",
+			},
+			err: "TCP health checks can't have a resource path or search string",
+		},
+		{
+			name: "Unknown type",
+			spec: HealthCheckSpec{Type: "ICMP", IPAddress: "10.0.0.1"},
+			err:  "unknown health check type ICMP",
+		},
+		{
+			name: "No endpoint",
+			spec: HealthCheckSpec{Type: "HTTP"},
+			err:  "health checks need an IP address or a domain name",
+		},
+		{
+			name: "Wrong interval",
+			spec: HealthCheckSpec{Type: "HTTP", IPAddress: "10.0.0.1", RequestInterval: 20},
+			err:  "request interval must be 10 or 30 seconds",
+		},
+		{
+			name: "Wrong threshold",
+			spec: HealthCheckSpec{Type: "HTTP", IPAddress: "10.0.0.1", FailureThreshold: 11},
+			err:  "failure threshold must be between 1 and 10",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := tc.spec.Config()
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error %q, received %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			if config.String() != tc.expected.String() {
+				t.Errorf("Expected %s, received %s", tc.expected, config)
+			}
+		})
+	}
+}
+
+func TestHealthChecks(t *testing.T) {
+	svc := newHealthChecksRoute53Client()
+	for i := 0; i < 12; i++ {
+		spec := &HealthCheckSpec{
+			Type:      "HTTP",
+			IPAddress: fmt.Sprintf("10.0.0.%d", i+1),
+		}
+		if i%2 == 0 {
+			spec.Name = fmt.Sprintf("web-%d", i)
+		}
+		if _, err := CreateHealthCheck(spec, svc); err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+	}
+	checks, err := ListHealthChecks(svc)
+	if err != nil || len(checks) != 12 {
+		t.Fatalf("Unexpected health checks %v, %v", checks, err)
+	}
+	names, err := HealthCheckNames(checks, svc)
+	if err != nil || len(names) != 6 || names["hc-11"] != "web-10" {
+		t.Errorf("Unexpected names %v, %v", names, err)
+	}
+
+	check, err := FindHealthCheck("web-10", svc)
+	if err != nil || *check.Id != "hc-11" {
+		t.Errorf("Unexpected health check %v, %v", check, err)
+	}
+	check, err = FindHealthCheck("hc-2", svc)
+	if err != nil || *check.Id != "hc-2" {
+		t.Errorf("Unexpected health check %v, %v", check, err)
+	}
+	if _, err = FindHealthCheck("web-3", svc); err == nil {
+		t.Error("Expected error finding missing health check")
+	}
+	svc.names["hc-3"] = "web-10"
+	_, err = FindHealthCheck("web-10", svc)
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous health check, received %v", err)
+	}
+
+	if err = DeleteHealthCheck("hc-2", svc); err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+	if checks, _ = ListHealthChecks(svc); len(checks) != 11 {
+		t.Errorf("Expected 11 health checks, received %d", len(checks))
+	}
+}
+
+func TestWriteHealthChecks(t *testing.T) {
+	checks := []*route53.HealthCheck{
+		{
+			Id: aws.String("hc-1"),
+			HealthCheckConfig: &route53.HealthCheckConfig{
+				Type:                     aws.String("HTTPS_STR_MATCH"),
+				FullyQualifiedDomainName: aws.String("api.example.com"),
+				Port:                     aws.Int64(443),
+				ResourcePath:             aws.String("/health"),
+				RequestInterval:          aws.Int64(10),
+				FailureThreshold:         aws.Int64(2),
+			},
+		},
+		{
+			Id: aws.String("hc-2"),
+			HealthCheckConfig: &route53.HealthCheckConfig{
+				Type:             aws.String("TCP"),
+				IPAddress:        aws.String("2001:db8::1"),
+				Port:             aws.Int64(5432),
+				RequestInterval:  aws.Int64(30),
+				FailureThreshold: aws.Int64(3),
+			},
+		},
+	}
+	buf := &bytes.Buffer{}
+	err := WriteHealthChecks(buf, checks, map[string]string{"hc-1": "api"})
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := `ID   NAME TYPE            ENDPOINT                           INTERVAL THRESHOLD
+hc-1 api  HTTPS_STR_MATCH https://api.example.com:443/health 10       2
+hc-2      TCP             [2001:db8::1]:5432                 30       3
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestCreateHealthCheckUnnamed(t *testing.T) {
+	svc := newHealthChecksRoute53Client()
+	svc.tagErr = fmt.Errorf("throttled")
+	check, err := CreateHealthCheck(
+		&HealthCheckSpec{Name: "web", Type: "HTTP", IPAddress: "10.0.0.1"},
+		svc,
+	)
+	if check != nil || err == nil ||
+		err.Error() != "unable to name health check hc-1, deleted it: throttled" {
+		t.Errorf("Unexpected health check %v, %v", check, err)
+	}
+	if len(svc.checks) != 0 {
+		t.Errorf("Expected the health check deleted, received %v", svc.checks)
+	}
+}
@@ -0,0 +1,173 @@
+package got
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// JournalEntry records a batch of changes applied to a zone, along with the
+// previous state of every record set it touched, so it can be rolled back.
+// Entries are recorded before applying their changes, without change IDs,
+// and again once applied, with them. ZoneID is the ID of the hosted zone
+// for Route53 zones.
+type JournalEntry struct {
+	ID        string                       `json:"id"`
+	ChangeIDs []string                     `json:"change_ids"`
+	Zone      string                       `json:"zone"`
+	ZoneID    string                       `json:"zone_id,omitempty"`
+	Time      time.Time                    `json:"time"`
+	User      string                       `json:"user"`
+	Changes   []*route53.Change            `json:"changes"`
+	Previous  []*route53.ResourceRecordSet `json:"previous"`
+}
+
+// NewJournalEntry returns the entry for applying changes to the zone, with
+// ID zoneID if hosted in Route53, whose record sets are currently the ones
+// in current.
+func NewJournalEntry(
+	zone string,
+	zoneID string,
+	changes []*route53.Change,
+	current []*route53.ResourceRecordSet,
+) *JournalEntry {
+	return &JournalEntry{
+		Zone:     zone,
+		ZoneID:   zoneID,
+		Time:     time.Now().UTC(),
+		User:     currentUser(),
+		Changes:  changes,
+		Previous: touchedRecordSets(changes, current),
+	}
+}
+
+// SetChangeIDs sets the IDs of the batches applied for the entry. The first
+// of them also identifies the entry.
+func (e *JournalEntry) SetChangeIDs(ids []string) {
+	e.ChangeIDs = ids
+	if len(ids) > 0 {
+		e.ID = shortChangeID(ids[0])
+	}
+}
+
+// RollbackChanges returns the changes needed for the record sets touched by
+// the entry to go back to their previous state, given the current record
+// sets of the zone.
+func (e *JournalEntry) RollbackChanges(
+	current []*route53.ResourceRecordSet,
+) []*route53.Change {
+	return DiffRecordSets(
+		touchedRecordSets(e.Changes, current),
+		e.Previous,
+		true,
+	)
+}
+
+// touchedRecordSets returns the record sets in list affected by the changes.
+func touchedRecordSets(
+	changes []*route53.Change,
+	list []*route53.ResourceRecordSet,
+) (touched []*route53.ResourceRecordSet) {
+	keys := map[string]bool{}
+	for _, change := range changes {
+		keys[recordSetKey(change.ResourceRecordSet)] = true
+	}
+	for _, rrs := range list {
+		if keys[recordSetKey(rrs)] {
+			touched = append(touched, rrs)
+		}
+	}
+	return
+}
+
+// currentUser returns the name of the user running the process.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// shortChangeID returns the change ID without the /change/ prefix Route53
+// adds to it.
+func shortChangeID(id string) string {
+	return strings.TrimPrefix(id, "/change/")
+}
+
+// Journal is a file keeping a JournalEntry per line, in JSON format.
+type Journal struct {
+	Path string
+}
+
+// NewJournal returns the Journal kept in path.
+func NewJournal(path string) *Journal {
+	return &Journal{Path: path}
+}
+
+// Record appends the entry to the journal, creating it if needed.
+func (j *Journal) Record(entry *JournalEntry) (err error) {
+	if err = os.MkdirAll(filepath.Dir(j.Path), 0700); err != nil {
+		return
+	}
+	file, err := os.OpenFile(
+		j.Path,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		0600,
+	)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_, err = file.Write(append(line, '\n'))
+	return
+}
+
+// Find returns the latest entry identified by id, which may be the ID of
+// any of its batches. Entries recorded before applying their changes are
+// never found, as they have no IDs yet.
+func (j *Journal) Find(id string) (found *JournalEntry, err error) {
+	file, err := os.Open(j.Path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	id = shortChangeID(id)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		entry := &JournalEntry{}
+		if err = json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, err
+		}
+		if len(entry.ChangeIDs) == 0 {
+			continue
+		}
+		if entry.ID == id {
+			found = entry
+			continue
+		}
+		for _, changeID := range entry.ChangeIDs {
+			if shortChangeID(changeID) == id {
+				found = entry
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if found == nil {
+		err = fmt.Errorf("change %s not found in journal", id)
+	}
+	return
+}
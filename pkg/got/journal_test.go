@@ -0,0 +1,93 @@
+package got
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var journalCurrent = []*route53.ResourceRecordSet{
+	newRecordSet("www.example.com.", "A", 300, "10.0.0.1"),
+	newRecordSet("old.example.com.", "A", 300, "10.0.0.2"),
+	newRecordSet("other.example.com.", "A", 300, "10.0.0.3"),
+}
+
+var journalChanges = []*route53.Change{
+	{
+		Action:            aws.String("UPSERT"),
+		ResourceRecordSet: newRecordSet("www.example.com.", "A", 60, "10.0.0.4"),
+	},
+	{
+		Action:            aws.String("DELETE"),
+		ResourceRecordSet: journalCurrent[1],
+	},
+	{
+		Action:            aws.String("CREATE"),
+		ResourceRecordSet: newRecordSet("new.example.com.", "A", 60, "10.0.0.5"),
+	},
+}
+
+func TestJournal(t *testing.T) {
+	journal := NewJournal(filepath.Join(t.TempDir(), "got", "journal.jsonl"))
+	entry := NewJournalEntry("example.com", "Z1", journalChanges, journalCurrent)
+	if len(entry.Previous) != 2 {
+		t.Errorf("Expected 2 previous record sets, received %d", len(entry.Previous))
+	}
+	if err := journal.Record(entry); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	entry.SetChangeIDs([]string{"/change/C1", "/change/C2"})
+	if err := journal.Record(entry); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	other := NewJournalEntry("example.com", "Z1", journalChanges[:1], journalCurrent)
+	other.SetChangeIDs([]string{"/change/C3"})
+	if err := journal.Record(other); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	for _, id := range []string{"C1", "/change/C2"} {
+		found, err := journal.Find(id)
+		if err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+		if found.ID != "C1" || len(found.Changes) != 3 ||
+			found.Zone != "example.com" || found.ZoneID != "Z1" {
+			t.Errorf("Unexpected entry %v", found)
+		}
+	}
+	for _, id := range []string{"C4", ""} {
+		_, err := journal.Find(id)
+		if err == nil || err.Error() != "change "+id+" not found in journal" {
+			t.Errorf("Expected missing change error, received %v", err)
+		}
+	}
+}
+
+func TestRollbackChanges(t *testing.T) {
+	entry := NewJournalEntry("example.com", "Z1", journalChanges, journalCurrent)
+	afterwards := []*route53.ResourceRecordSet{
+		journalChanges[0].ResourceRecordSet,
+		journalCurrent[2],
+		journalChanges[2].ResourceRecordSet,
+	}
+	expected := []string{
+		"DELETE new.example.com.",
+		"CREATE old.example.com.",
+		"UPSERT www.example.com.",
+	}
+	changes := entry.RollbackChanges(afterwards)
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, received %v", len(expected), changes)
+	}
+	for i, change := range changes {
+		out := *change.Action + " " + *change.ResourceRecordSet.Name
+		if out != expected[i] {
+			t.Errorf("Expected %s, received %s", expected[i], out)
+		}
+	}
+	if *changes[2].ResourceRecordSet.TTL != 300 {
+		t.Errorf("Expected previous TTL restored, received %v", changes[2])
+	}
+}
@@ -0,0 +1,294 @@
+package got
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// Severity tells how serious a Finding is.
+type Severity int
+
+// Severities of findings, from least to most serious.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity returns the Severity named s.
+func ParseSeverity(s string) (Severity, error) {
+	for i, name := range severityNames {
+		if strings.EqualFold(s, name) {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %s", s)
+}
+
+// ttlOutlierFactor is how many times the TTL of a record set must be lower
+// or higher than the median of the zone to be reported.
+const ttlOutlierFactor = 10
+
+// Finding is a problem found by Lint in a record set.
+type Finding struct {
+	Severity Severity
+	Check    string
+	Name     string
+	Type     string
+	Message  string
+}
+
+// Lint performs static checks over the record sets of zone, returning the
+// problems found sorted by name, type and check.
+func Lint(
+	records []*route53.ResourceRecordSet,
+	zone string,
+) (findings []*Finding) {
+	l := newLinter(records, zone)
+	for _, rrs := range records {
+		l.checkCNAME(rrs)
+		l.checkDuplicates(rrs)
+		l.checkTTL(rrs)
+		l.checkTargets(rrs)
+	}
+	sortFindings(l.findings)
+	return l.findings
+}
+
+// sortFindings sorts the findings by name, type and check.
+func sortFindings(findings []*Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Check < b.Check
+	})
+}
+
+// HighestSeverity returns the highest severity among the findings, and
+// whether there was any.
+func HighestSeverity(findings []*Finding) (highest Severity, found bool) {
+	for _, f := range findings {
+		if !found || f.Severity > highest {
+			highest = f.Severity
+		}
+		found = true
+	}
+	return
+}
+
+// WriteFindings writes the findings as a table.
+func WriteFindings(w io.Writer, findings []*Finding) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tCHECK\tNAME\tTYPE\tMESSAGE")
+	for _, f := range findings {
+		fmt.Fprintf(
+			tw,
+			"%s\t%s\t%s\t%s\t%s\n",
+			f.Severity,
+			f.Check,
+			f.Name,
+			f.Type,
+			f.Message,
+		)
+	}
+	return tw.Flush()
+}
+
+// linter keeps the state needed by the checks of Lint.
+type linter struct {
+	zone string
+	// types has the types of the record sets by lowercased name, in master
+	// file syntax.
+	types     map[string][]string
+	medianTTL int64
+	findings  []*Finding
+}
+
+func newLinter(records []*route53.ResourceRecordSet, zone string) *linter {
+	l := &linter{
+		zone:  strings.ToLower(Fqdn(zone)),
+		types: map[string][]string{},
+	}
+	ttls := []int64{}
+	for _, rrs := range records {
+		name := l.key(*rrs.Name)
+		l.types[name] = append(l.types[name], *rrs.Type)
+		if rrs.TTL != nil {
+			ttls = append(ttls, *rrs.TTL)
+		}
+	}
+	if len(ttls) > 0 {
+		sort.Slice(ttls, func(i, j int) bool { return ttls[i] < ttls[j] })
+		l.medianTTL = ttls[len(ttls)/2]
+	}
+	return l
+}
+
+// key returns the name in the form used to index types.
+func (l *linter) key(name string) string {
+	return strings.ToLower(zoneFileName(Fqdn(name)))
+}
+
+func (l *linter) report(
+	rrs *route53.ResourceRecordSet,
+	severity Severity,
+	check string,
+	format string,
+	a ...interface{},
+) {
+	l.findings = append(l.findings, &Finding{
+		Severity: severity,
+		Check:    check,
+		Name:     zoneFileName(*rrs.Name),
+		Type:     *rrs.Type,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// inZone returns whether name belongs to the zone.
+func (l *linter) inZone(name string) bool {
+	name = l.key(name)
+	return name == l.zone || strings.HasSuffix(name, "."+l.zone)
+}
+
+// exists returns whether name has any record set, directly or through a
+// wildcard.
+func (l *linter) exists(name string) bool {
+	name = l.key(name)
+	if _, found := l.types[name]; found {
+		return true
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		_, found := l.types["*"+name[i:]]
+		return found
+	}
+	return false
+}
+
+// isCNAME returns whether name has a CNAME record set.
+func (l *linter) isCNAME(name string) bool {
+	for _, typ := range l.types[l.key(name)] {
+		if typ == "CNAME" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCNAME reports CNAME record sets at the apex, next to other types or
+// pointing to names in the zone without records.
+func (l *linter) checkCNAME(rrs *route53.ResourceRecordSet) {
+	if *rrs.Type != "CNAME" {
+		return
+	}
+	if l.key(*rrs.Name) == l.zone {
+		l.report(rrs, SeverityError, "cname-apex", "CNAME at the zone apex")
+	}
+	others := []string{}
+	for _, typ := range l.types[l.key(*rrs.Name)] {
+		if typ != "CNAME" {
+			others = append(others, typ)
+		}
+	}
+	if len(others) > 0 {
+		l.report(
+			rrs,
+			SeverityError,
+			"cname-conflict",
+			"CNAME next to %s records",
+			strings.Join(others, ", "),
+		)
+	}
+	for _, rr := range rrs.ResourceRecords {
+		target := *rr.Value
+		if l.inZone(target) && !l.exists(target) {
+			l.report(
+				rrs,
+				SeverityError,
+				"cname-dangling",
+				"target %s doesn't exist",
+				target,
+			)
+		}
+	}
+}
+
+// checkDuplicates reports values repeated in the record set.
+func (l *linter) checkDuplicates(rrs *route53.ResourceRecordSet) {
+	values := recordSetValues(rrs)
+	for i := 1; i < len(values); i++ {
+		if values[i] == values[i-1] && (i < 2 || values[i] != values[i-2]) {
+			l.report(
+				rrs,
+				SeverityWarning,
+				"duplicate-value",
+				"value %s is duplicated",
+				values[i],
+			)
+		}
+	}
+}
+
+// checkTTL reports record sets with a TTL much lower or higher than the
+// median of the zone.
+func (l *linter) checkTTL(rrs *route53.ResourceRecordSet) {
+	if rrs.TTL == nil || l.medianTTL == 0 {
+		return
+	}
+	ttl := *rrs.TTL
+	if ttl*ttlOutlierFactor <= l.medianTTL ||
+		ttl >= l.medianTTL*ttlOutlierFactor {
+		l.report(
+			rrs,
+			SeverityInfo,
+			"ttl-outlier",
+			"TTL %d is far from the zone median of %d",
+			ttl,
+			l.medianTTL,
+		)
+	}
+}
+
+// checkTargets reports MX and SRV records pointing to CNAMEs, which is
+// forbidden by RFC 2181.
+func (l *linter) checkTargets(rrs *route53.ResourceRecordSet) {
+	if *rrs.Type != "MX" && *rrs.Type != "SRV" {
+		return
+	}
+	for _, rr := range rrs.ResourceRecords {
+		fields := strings.Fields(*rr.Value)
+		if len(fields) == 0 {
+			continue
+		}
+		target := fields[len(fields)-1]
+		if l.isCNAME(target) {
+			l.report(
+				rrs,
+				SeverityError,
+				"target-cname",
+				"target %s is a CNAME",
+				target,
+			)
+		}
+	}
+}
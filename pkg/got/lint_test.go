@@ -0,0 +1,85 @@
+package got
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var lintZone = `$TTL 300
+@	IN	SOA	ns1 hostmaster 1 7200 900 1209600 86400
+@	IN	NS	ns1
+@	IN	MX	10 mail
+@	IN	MX	20 mx.example.net.
+ns1	IN	A	10.0.0.53
+mail	IN	CNAME	mailserver
+mailserver	IN	A	10.0.0.25
+www	IN	CNAME	web
+www	IN	TXT	"site"
+web	IN	A	10.0.0.80
+old	IN	CNAME	gone
+ext	IN	CNAME	www.example.net.
+wild	IN	CNAME	foo.apps
+*.apps	IN	A	10.0.0.80
+_sip._tcp	IN	SRV	10 5 5060 mail
+cache	1	IN	A	10.0.0.9
+`
+
+func TestLint(t *testing.T) {
+	records, err := ParseZoneFile(strings.NewReader(lintZone), "example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	records = append(
+		records,
+		newRecordSet("example.com.", "CNAME", 300, "www.example.com."),
+		newRecordSet("dup.example.com.", "A", 300, "10.0.0.1", "10.0.0.1", "10.0.0.1"),
+		&route53.ResourceRecordSet{
+			Name: aws.String("lb.example.com."),
+			Type: aws.String("A"),
+			AliasTarget: &route53.AliasTarget{
+				DNSName:      aws.String("lb.elb.amazonaws.com."),
+				HostedZoneId: aws.String("Z35SXDOTRQ7X7K"),
+			},
+		},
+	)
+	out := Lint(records, "example.com")
+	buf := &bytes.Buffer{}
+	if err := WriteFindings(buf, out); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := `SEVERITY CHECK           NAME                   TYPE  MESSAGE
+error    target-cname    _sip._tcp.example.com. SRV   target mail.example.com. is a CNAME
+info     ttl-outlier     cache.example.com.     A     TTL 1 is far from the zone median of 300
+warning  duplicate-value dup.example.com.       A     value 10.0.0.1 is duplicated
+error    cname-apex      example.com.           CNAME CNAME at the zone apex
+error    cname-conflict  example.com.           CNAME CNAME next to SOA, NS, MX records
+error    target-cname    example.com.           MX    target mail.example.com. is a CNAME
+error    cname-dangling  old.example.com.       CNAME target gone.example.com. doesn't exist
+error    cname-conflict  www.example.com.       CNAME CNAME next to TXT records
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected findings:\n%s", buf.String())
+	}
+	if severity, found := HighestSeverity(out); !found || severity != SeverityError {
+		t.Errorf("Expected highest severity error, received %s", severity)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		out, err := ParseSeverity(strings.ToUpper(s.String()))
+		if err != nil || out != s {
+			t.Errorf("Expected %s, received %s, %v", s, out, err)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("Expected unknown severity to fail")
+	}
+	if _, found := HighestSeverity(nil); found {
+		t.Error("Expected no severity for no findings")
+	}
+}
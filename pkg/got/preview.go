@@ -0,0 +1,208 @@
+package got
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// ANSI escape sequences used to colorize diffs.
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorReset = "\x1b[0m"
+)
+
+// RecordSetDiff is the state of a record set before and after a change.
+// Before is nil for record sets created, and After for those deleted.
+// Unchanged is set when the change leaves the record set as it was.
+type RecordSetDiff struct {
+	Action    string  `json:"action"`
+	Before    *Record `json:"before,omitempty"`
+	After     *Record `json:"after,omitempty"`
+	Unchanged bool    `json:"unchanged"`
+}
+
+// DiffSummary counts the record sets affected by changes.
+type DiffSummary struct {
+	Create    int `json:"create"`
+	Update    int `json:"update"`
+	Delete    int `json:"delete"`
+	Unchanged int `json:"unchanged"`
+}
+
+// String describes the summary in a line.
+func (s DiffSummary) String() string {
+	return fmt.Sprintf(
+		"%d to create, %d to update, %d to delete, %d unchanged",
+		s.Create,
+		s.Update,
+		s.Delete,
+		s.Unchanged,
+	)
+}
+
+// PreviewChanges returns the state before and after the changes of every
+// record set they affect, given the current record sets of the zone.
+func PreviewChanges(
+	changes []*route53.Change,
+	current []*route53.ResourceRecordSet,
+) (diffs []*RecordSetDiff) {
+	existing := map[string]*route53.ResourceRecordSet{}
+	for _, rrs := range current {
+		existing[recordSetKey(rrs)] = rrs
+	}
+	for _, change := range changes {
+		d := &RecordSetDiff{Action: *change.Action}
+		old, found := existing[recordSetKey(change.ResourceRecordSet)]
+		if found {
+			record := NewRecord(old)
+			d.Before = &record
+		}
+		if *change.Action != route53.ChangeActionDelete {
+			record := NewRecord(change.ResourceRecordSet)
+			d.After = &record
+			d.Unchanged = found && recordSetEqual(old, change.ResourceRecordSet)
+		}
+		diffs = append(diffs, d)
+	}
+	return
+}
+
+// SummarizeDiffs counts the record sets created, updated, deleted or left
+// unchanged.
+func SummarizeDiffs(diffs []*RecordSetDiff) (s DiffSummary) {
+	for _, d := range diffs {
+		switch {
+		case d.After == nil:
+			s.Delete++
+		case d.Before == nil:
+			s.Create++
+		case d.Unchanged:
+			s.Unchanged++
+		default:
+			s.Update++
+		}
+	}
+	return
+}
+
+// WriteDiffs writes the diffs in unified format, one line per value,
+// followed by a summary. Lines are colorized with ANSI escape sequences if
+// color is set.
+func WriteDiffs(w io.Writer, diffs []*RecordSetDiff, color bool) error {
+	for _, d := range diffs {
+		record := d.After
+		if record == nil {
+			record = d.Before
+		}
+		header := fmt.Sprintf("%s %s", record.Name, record.Type)
+		if record.SetIdentifier != "" {
+			header += " " + record.SetIdentifier
+		}
+		var before, after []string
+		if d.Before != nil {
+			before = recordLines(d.Before)
+		}
+		if d.After != nil {
+			after = recordLines(d.After)
+		}
+		if err := writeDiff(w, header, d.Action, before, after, color); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, SummarizeDiffs(diffs))
+	return err
+}
+
+// writeDiff writes the lines describing a record set before and after a
+// change in unified format, under a header naming the record set.
+func writeDiff(
+	w io.Writer,
+	header, action string,
+	before, after []string,
+	color bool,
+) error {
+	paint := func(c, s string) string {
+		if !color {
+			return s
+		}
+		return c + s + colorReset
+	}
+	if _, err := fmt.Fprintf(
+		w,
+		"%s\n%s\n%s\n",
+		paint(colorRed, "--- "+header),
+		paint(colorGreen, "+++ "+header),
+		paint(colorCyan, "@@ "+action+" @@"),
+	); err != nil {
+		return err
+	}
+	kept := map[string]bool{}
+	for _, line := range after {
+		kept[line] = true
+	}
+	for _, line := range before {
+		out := paint(colorRed, "-"+line)
+		if kept[line] {
+			out = " " + line
+		}
+		if _, err := fmt.Fprintln(w, out); err != nil {
+			return err
+		}
+	}
+	kept = map[string]bool{}
+	for _, line := range before {
+		kept[line] = true
+	}
+	for _, line := range after {
+		if kept[line] {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, paint(colorGreen, "+"+line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDiffsJSON writes the diffs and their summary as a JSON object.
+func WriteDiffsJSON(w io.Writer, diffs []*RecordSetDiff) error {
+	if diffs == nil {
+		diffs = []*RecordSetDiff{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Changes []*RecordSetDiff `json:"changes"`
+		Summary DiffSummary      `json:"summary"`
+	}{
+		Changes: diffs,
+		Summary: SummarizeDiffs(diffs),
+	})
+}
+
+// recordLines returns a line per value of the record, in master file
+// syntax.
+func recordLines(r *Record) (lines []string) {
+	ttl := "-"
+	if r.Alias == "" {
+		ttl = fmt.Sprint(r.TTL)
+	}
+	for _, value := range r.Values {
+		lines = append(
+			lines,
+			fmt.Sprintf("%s\t%s\t%s\t%s", r.Name, ttl, r.Type, value),
+		)
+	}
+	if r.Alias != "" {
+		lines = append(
+			lines,
+			fmt.Sprintf("%s\t%s\t%s\tALIAS %s", r.Name, ttl, r.Type, r.Alias),
+		)
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package got
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var previewCurrent = []*route53.ResourceRecordSet{
+	newRecordSet("www.example.com.", "A", 300, "10.0.0.1", "10.0.0.2"),
+	newRecordSet("old.example.com.", "CNAME", 300, "www.example.com."),
+	newRecordSet("same.example.com.", "A", 60, "10.0.0.9"),
+}
+
+var previewChanges = []*route53.Change{
+	{
+		Action:            aws.String("UPSERT"),
+		ResourceRecordSet: newRecordSet("www.example.com.", "A", 300, "10.0.0.1", "10.0.0.3"),
+	},
+	{
+		Action:            aws.String("DELETE"),
+		ResourceRecordSet: previewCurrent[1],
+	},
+	{
+		Action:            aws.String("CREATE"),
+		ResourceRecordSet: newRecordSet("new.example.com.", "TXT", 60, "\"hello\""),
+	},
+	{
+		Action:            aws.String("UPSERT"),
+		ResourceRecordSet: newRecordSet("same.example.com.", "A", 60, "10.0.0.9"),
+	},
+}
+
+func TestWriteDiffs(t *testing.T) {
+	diffs := PreviewChanges(previewChanges, previewCurrent)
+	buf := &bytes.Buffer{}
+	if err := WriteDiffs(buf, diffs, false); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := `--- www.example.com. A
++++ www.example.com. A
+@@ UPSERT @@
+ www.example.com.	300	A	10.0.0.1
+-www.example.com.	300	A	10.0.0.2
++www.example.com.	300	A	10.0.0.3
+--- old.example.com. CNAME
++++ old.example.com. CNAME
+@@ DELETE @@
+-old.example.com.	300	CNAME	www.example.com.
+--- new.example.com. TXT
++++ new.example.com. TXT
+@@ CREATE @@
++new.example.com.	60	TXT	"hello"
+--- same.example.com. A
++++ same.example.com. A
+@@ UPSERT @@
+ same.example.com.	60	A	10.0.0.9
+1 to create, 1 to update, 1 to delete, 1 unchanged
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected diff:\n%s", buf.String())
+	}
+}
+
+func TestWriteDiffsColor(t *testing.T) {
+	diffs := PreviewChanges(previewChanges[1:2], previewCurrent)
+	buf := &bytes.Buffer{}
+	if err := WriteDiffs(buf, diffs, true); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	line := colorRed + "-old.example.com.\t300\tCNAME\twww.example.com." + colorReset
+	if !strings.Contains(buf.String(), line) {
+		t.Errorf("Expected colorized removal, received %q", buf.String())
+	}
+}
+
+func TestWriteDiffsJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	diffs := PreviewChanges(previewChanges, previewCurrent)
+	if err := WriteDiffsJSON(buf, diffs); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	out := struct {
+		Changes []*RecordSetDiff
+		Summary DiffSummary
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Invalid JSON %s", err)
+	}
+	if len(out.Changes) != 4 || out.Changes[1].After != nil ||
+		out.Changes[2].Before != nil || !out.Changes[3].Unchanged {
+		t.Errorf("Unexpected changes %s", buf.String())
+	}
+	if out.Summary != (DiffSummary{Create: 1, Update: 1, Delete: 1, Unchanged: 1}) {
+		t.Errorf("Unexpected summary %v", out.Summary)
+	}
+}
@@ -0,0 +1,79 @@
+package got
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// Provider is a DNS service holding the records of a zone. Records and
+// changes are described with the Route53 types regardless of the service.
+type Provider interface {
+	// List returns every record set in the zone.
+	List() ([]*route53.ResourceRecordSet, error)
+	// Apply performs the changes in the zone, returning an identifier per
+	// batch of changes sent.
+	Apply(changes []*route53.Change) ([]string, error)
+	// ChangeChecker reports whether the batches of changes applied are
+	// visible in the zone.
+	ChangeChecker
+}
+
+// Ensure the providers implement the interface.
+var _ Provider = &Route53Provider{}
+var _ Provider = &RFC2136Provider{}
+
+// Route53Provider is the Provider for a Route53 hosted zone.
+type Route53Provider struct {
+	ZoneID string
+	svc    route53iface.Route53API
+}
+
+// NewRoute53Provider returns the Provider for the hosted zone named
+// zoneName.
+func NewRoute53Provider(
+	zoneName string,
+	svc route53iface.Route53API,
+) (*Route53Provider, error) {
+	zoneID, err := GetZoneID(zoneName, svc)
+	if err != nil {
+		return nil, err
+	}
+	return NewRoute53ProviderByID(zoneID, svc), nil
+}
+
+// NewRoute53ProviderByID returns the Provider for the hosted zone with ID
+// zoneID.
+func NewRoute53ProviderByID(
+	zoneID string,
+	svc route53iface.Route53API,
+) *Route53Provider {
+	return &Route53Provider{
+		ZoneID: zoneID,
+		svc:    svc,
+	}
+}
+
+// List returns every record set in the hosted zone.
+func (p *Route53Provider) List() ([]*route53.ResourceRecordSet, error) {
+	return GetResourceRecordSet(p.ZoneID, p.svc)
+}
+
+// Apply performs the changes in the hosted zone, split in batches, and
+// returns the ID of the change of each batch.
+func (p *Route53Provider) Apply(changes []*route53.Change) (ids []string, err error) {
+	changeInfos, err := ApplyChanges(changes, &p.ZoneID, p.svc)
+	for _, changeInfo := range changeInfos {
+		ids = append(ids, *changeInfo.Id)
+	}
+	return
+}
+
+// Status returns whether the change identified by id is INSYNC.
+func (p *Route53Provider) Status(
+	ctx context.Context,
+	id string,
+) (done bool, status string, err error) {
+	return (&route53ChangeChecker{svc: p.svc}).Status(ctx, id)
+}
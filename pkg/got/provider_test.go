@@ -0,0 +1,148 @@
+package got
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+)
+
+func TestRoute53Provider(t *testing.T) {
+	mockSvc := &mockRoute53Client{}
+	p, err := NewRoute53Provider("test", mockSvc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if p.ZoneID != "test" {
+		t.Errorf("Unexpected zone ID %s", p.ZoneID)
+	}
+	list, err := p.List()
+	if err != nil || len(list) != len(ResourceRecordSetList) {
+		t.Errorf("Unexpected list %v, %v", list, err)
+	}
+	ids, err := p.Apply([]*route53.Change{
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: onerecordA,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(ids) != 1 || ids[0] != "test" {
+		t.Errorf("Unexpected change IDs %v", ids)
+	}
+	done, status, err := p.Status(context.Background(), ids[0])
+	if err != nil || !done || status != route53.ChangeStatusInsync {
+		t.Errorf("Unexpected status %s, %v", status, err)
+	}
+}
+
+func TestRoute53ProviderWithFake(t *testing.T) {
+	svc := mocks.NewRoute53Client()
+	svc.PageSize = 3
+	svc.PendingPolls = 2
+	public := svc.AddHostedZone("example.com")
+	private := svc.AddHostedZone("example.com", "vpc-1")
+
+	zone, err := FindHostedZone("example.com", &ZoneSelector{VPCID: "vpc-1"}, svc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if *zone.Id != private {
+		t.Errorf("Expected zone %s, received %s", private, *zone.Id)
+	}
+
+	// Weighted record sets share name and type, so listing them across
+	// pages needs the set identifier. UPSERT changes count twice, so
+	// 600 of them need two batches.
+	changes := []*route53.Change{}
+	for _, id := range []string{"blue", "green", "red"} {
+		rrs := newRecordSet("api.example.com.", "A", 60, "10.0.0.1")
+		rrs.SetIdentifier = aws.String(id)
+		rrs.Weight = aws.Int64(1)
+		changes = append(changes, &route53.Change{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: rrs,
+		})
+	}
+	for i := 0; i < 600; i++ {
+		changes = append(changes, &route53.Change{
+			Action: aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet(
+				fmt.Sprintf("host-%03d.example.com.", i),
+				"A",
+				300,
+				"10.0.1.1",
+			),
+		})
+	}
+	p := NewRoute53ProviderByID(public, svc)
+	ids, err := p.Apply(changes)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 batches, received %v", ids)
+	}
+	polls := 0
+	w := &Waiter{
+		Interval:    time.Millisecond,
+		MaxInterval: time.Millisecond,
+		Progress:    func(WaitProgress) { polls++ },
+	}
+	if err = w.Wait(context.Background(), p, ids...); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if polls != 4 {
+		t.Errorf("Expected 4 polls, received %d", polls)
+	}
+
+	list, err := p.List()
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(list) != 2+len(changes) {
+		t.Fatalf("Expected %d record sets, received %d", 2+len(changes), len(list))
+	}
+	seen := map[string]bool{}
+	for _, rrs := range list {
+		if seen[recordSetKey(rrs)] {
+			t.Errorf("Record set %s listed twice", recordSetKey(rrs))
+		}
+		seen[recordSetKey(rrs)] = true
+	}
+
+	// Changes are applied atomically, so the failing DELETE undoes the
+	// UPSERT before it.
+	_, err = p.Apply([]*route53.Change{
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet("www.example.com.", "A", 60, "10.0.0.1"),
+		},
+		{
+			Action:            aws.String("DELETE"),
+			ResourceRecordSet: newRecordSet("host-000.example.com.", "A", 60, "10.0.1.1"),
+		},
+	})
+	if err == nil {
+		t.Error("Expected error deleting record set with different TTL")
+	}
+	_, err = p.Apply([]*route53.Change{
+		{
+			Action:            aws.String("CREATE"),
+			ResourceRecordSet: newRecordSet("HOST-001.example.com", "A", 300, "10.0.1.2"),
+		},
+	})
+	if err == nil {
+		t.Error("Expected error creating existing record set")
+	}
+	if after, _ := p.List(); len(after) != len(list) {
+		t.Errorf("Expected %d record sets, received %d", len(list), len(after))
+	}
+}
@@ -0,0 +1,401 @@
+package got
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"github.com/miekg/dns"
+)
+
+// ReverseZones are the reverse hosted zones of an account, in-addr.arpa and
+// ip6.arpa subdomains, holding the PTR records of addresses. Their record
+// sets are only listed when needed.
+type ReverseZones struct {
+	zones   []*route53.HostedZone
+	records map[string][]*route53.ResourceRecordSet
+	svc     route53iface.Route53API
+}
+
+// ZoneChanges are changes to the record sets of a hosted zone, along with
+// its current record sets.
+type ZoneChanges struct {
+	Zone    *route53.HostedZone
+	Current []*route53.ResourceRecordSet
+	Changes []*route53.Change
+}
+
+// NewReverseZones returns the reverse hosted zones of the account among
+// those selected.
+func NewReverseZones(
+	selector *ZoneSelector,
+	svc route53iface.Route53API,
+) (r *ReverseZones, err error) {
+	zones, err := GetHostedZones(selector, svc)
+	if err != nil {
+		return
+	}
+	r = &ReverseZones{
+		records: map[string][]*route53.ResourceRecordSet{},
+		svc:     svc,
+	}
+	for _, zone := range zones {
+		if isReverseName(*zone.Name) {
+			r.zones = append(r.zones, zone)
+		}
+	}
+	return
+}
+
+// isReverseName returns whether name is under in-addr.arpa or ip6.arpa.
+func isReverseName(name string) bool {
+	name = strings.ToLower(Fqdn(name))
+	return strings.HasSuffix(name, ".in-addr.arpa.") ||
+		strings.HasSuffix(name, ".ip6.arpa.")
+}
+
+// Zone returns the deepest reverse zone holding the record named name, or
+// nil if there's none. It fails if more than one zone has that name.
+func (r *ReverseZones) Zone(name string) (zone *route53.HostedZone, err error) {
+	name = strings.ToLower(Fqdn(name))
+	depth := 0
+	for _, hz := range r.zones {
+		apex := strings.ToLower(Fqdn(*hz.Name))
+		if name != apex && !strings.HasSuffix(name, "."+apex) {
+			continue
+		}
+		switch {
+		case len(apex) > depth:
+			zone, depth = hz, len(apex)
+		case len(apex) == depth:
+			err = fmt.Errorf(
+				"reverse zone %s is ambiguous, it matches %s and %s",
+				apex,
+				describeZone(zone),
+				describeZone(hz),
+			)
+			return
+		}
+	}
+	return
+}
+
+// RecordSets returns the record sets of the reverse zone.
+func (r *ReverseZones) RecordSets(
+	zone *route53.HostedZone,
+) (list []*route53.ResourceRecordSet, err error) {
+	list, found := r.records[*zone.Id]
+	if found {
+		return
+	}
+	if list, err = GetResourceRecordSet(*zone.Id, r.svc); err != nil {
+		return
+	}
+	r.records[*zone.Id] = list
+	return
+}
+
+// findPTR returns the PTR record set named name in the reverse zone, or nil
+// if there's none.
+func (r *ReverseZones) findPTR(
+	zone *route53.HostedZone,
+	name string,
+) (*route53.ResourceRecordSet, error) {
+	list, err := r.RecordSets(zone)
+	if err != nil {
+		return nil, err
+	}
+	return findRoutedRecordSet(list, name, "PTR", ""), nil
+}
+
+// ptrTarget returns the name PTR records of the addresses of the A or AAAA
+// record set point at, or nothing if they shouldn't have PTR records, as
+// for alias and wildcard records.
+func ptrTarget(rrs *route53.ResourceRecordSet) string {
+	switch *rrs.Type {
+	case "A", "AAAA":
+	default:
+		return ""
+	}
+	name := strings.ToLower(zoneFileName(*rrs.Name))
+	if rrs.AliasTarget != nil || strings.HasPrefix(name, "*.") {
+		return ""
+	}
+	return Fqdn(name)
+}
+
+// PTRChanges returns the changes to the reverse zones keeping the PTR
+// records in sync with the changes to the A and AAAA records of a zone,
+// whose record sets are currently those in current. The PTR records of
+// addresses added point at the name of the record, replacing any other
+// name, with the TTL of the record if new. Those of addresses removed stop
+// pointing at it, unless another record set with that name still holds
+// them after the changes, as weighted or failover sets do, and are deleted
+// if left without names. It fails if an address added has no reverse zone.
+func (r *ReverseZones) PTRChanges(
+	changes []*route53.Change,
+	current []*route53.ResourceRecordSet,
+) (zoneChanges []*ZoneChanges, err error) {
+	existing := map[string]*route53.ResourceRecordSet{}
+	after := map[string]*route53.ResourceRecordSet{}
+	for _, rrs := range current {
+		existing[recordSetKey(rrs)] = rrs
+		after[recordSetKey(rrs)] = rrs
+	}
+	for _, change := range changes {
+		key := recordSetKey(change.ResourceRecordSet)
+		if *change.Action == route53.ChangeActionDelete {
+			delete(after, key)
+			continue
+		}
+		after[key] = change.ResourceRecordSet
+	}
+	// Addresses held by every name after the changes.
+	held := map[string]map[string]bool{}
+	for _, rrs := range after {
+		target := ptrTarget(rrs)
+		if target == "" {
+			continue
+		}
+		if held[target] == nil {
+			held[target] = map[string]bool{}
+		}
+		for _, value := range recordSetValues(rrs) {
+			held[target][value] = true
+		}
+	}
+	// Names every PTR record should point at, nil if none, in the order
+	// they're first touched.
+	targets := map[string][]string{}
+	order := []string{}
+	addresses := map[string]string{}
+	ttls := map[string]int64{}
+	touch := func(address string) (name string, err error) {
+		if name, err = dns.ReverseAddr(address); err != nil {
+			return
+		}
+		name = strings.ToLower(name)
+		if _, found := targets[name]; found {
+			return
+		}
+		order = append(order, name)
+		targets[name] = nil
+		addresses[name] = address
+		var zone *route53.HostedZone
+		if zone, err = r.Zone(name); err != nil || zone == nil {
+			return
+		}
+		var ptr *route53.ResourceRecordSet
+		if ptr, err = r.findPTR(zone, name); err != nil || ptr == nil {
+			return
+		}
+		targets[name] = recordSetValues(ptr)
+		return
+	}
+	for _, change := range changes {
+		target := ptrTarget(change.ResourceRecordSet)
+		if target == "" {
+			continue
+		}
+		added := map[string]bool{}
+		if *change.Action != route53.ChangeActionDelete {
+			for _, value := range recordSetValues(change.ResourceRecordSet) {
+				added[value] = true
+			}
+		}
+		if old := existing[recordSetKey(change.ResourceRecordSet)]; old != nil {
+			for _, value := range recordSetValues(old) {
+				if held[target][value] {
+					continue
+				}
+				var name string
+				if name, err = touch(value); err != nil {
+					return
+				}
+				targets[name] = removeTarget(targets[name], target)
+			}
+		}
+		for _, value := range recordSetValues(change.ResourceRecordSet) {
+			if !added[value] {
+				continue
+			}
+			var name string
+			if name, err = touch(value); err != nil {
+				return
+			}
+			targets[name] = []string{target}
+			ttls[name] = aws.Int64Value(change.ResourceRecordSet.TTL)
+		}
+	}
+	byZone := map[string]*ZoneChanges{}
+	for _, name := range order {
+		var zone *route53.HostedZone
+		if zone, err = r.Zone(name); err != nil {
+			return
+		}
+		if zone == nil {
+			if len(targets[name]) > 0 {
+				err = fmt.Errorf("no reverse zone for %s", addresses[name])
+				return
+			}
+			continue
+		}
+		var ptr *route53.ResourceRecordSet
+		if ptr, err = r.findPTR(zone, name); err != nil {
+			return
+		}
+		change := ptrChange(ptr, name, targets[name], ttls[name])
+		if change == nil {
+			continue
+		}
+		if byZone[*zone.Id] == nil {
+			byZone[*zone.Id] = &ZoneChanges{
+				Zone:    zone,
+				Current: r.records[*zone.Id],
+			}
+			zoneChanges = append(zoneChanges, byZone[*zone.Id])
+		}
+		byZone[*zone.Id].Changes = append(byZone[*zone.Id].Changes, change)
+	}
+	sort.SliceStable(zoneChanges, func(i, j int) bool {
+		return *zoneChanges[i].Zone.Name < *zoneChanges[j].Zone.Name
+	})
+	return
+}
+
+// removeTarget returns the names a PTR record points at without target.
+func removeTarget(names []string, target string) (result []string) {
+	for _, name := range names {
+		if !strings.EqualFold(Fqdn(name), target) {
+			result = append(result, name)
+		}
+	}
+	return
+}
+
+// ptrChange returns the change making the PTR record named name, currently
+// ptr, point at targets, or nil if it already does.
+func ptrChange(
+	ptr *route53.ResourceRecordSet,
+	name string,
+	targets []string,
+	ttl int64,
+) *route53.Change {
+	if ptr == nil {
+		if len(targets) == 0 {
+			return nil
+		}
+		return &route53.Change{
+			Action: aws.String(route53.ChangeActionUpsert),
+			ResourceRecordSet: &route53.ResourceRecordSet{
+				Name:            aws.String(name),
+				Type:            aws.String("PTR"),
+				TTL:             aws.Int64(ttl),
+				ResourceRecords: NewResourceRecordList(targets),
+			},
+		}
+	}
+	if strings.EqualFold(
+		strings.Join(recordSetValues(ptr), " "),
+		strings.Join(targets, " "),
+	) {
+		return nil
+	}
+	if len(targets) == 0 {
+		return &route53.Change{
+			Action:            aws.String(route53.ChangeActionDelete),
+			ResourceRecordSet: ptr,
+		}
+	}
+	rrs := *ptr
+	rrs.ResourceRecords = NewResourceRecordList(targets)
+	return &route53.Change{
+		Action:            aws.String(route53.ChangeActionUpsert),
+		ResourceRecordSet: &rrs,
+	}
+}
+
+// Audit checks the PTR records of the reverse zones against the record
+// sets of the zones named, returning the A and AAAA records whose
+// addresses have no PTR record, and the PTR records pointing at names in
+// those zones without records, sorted by name, type and check.
+func (r *ReverseZones) Audit(
+	zones []string,
+	records []*route53.ResourceRecordSet,
+) (findings []*Finding, err error) {
+	names := map[string]bool{}
+	for _, rrs := range records {
+		names[strings.ToLower(Fqdn(zoneFileName(*rrs.Name)))] = true
+	}
+	for _, rrs := range records {
+		if ptrTarget(rrs) == "" {
+			continue
+		}
+		for _, value := range recordSetValues(rrs) {
+			var name string
+			if name, err = dns.ReverseAddr(value); err != nil {
+				return
+			}
+			var zone *route53.HostedZone
+			if zone, err = r.Zone(name); err != nil {
+				return
+			}
+			message := fmt.Sprintf("no reverse zone for %s", value)
+			if zone != nil {
+				var ptr *route53.ResourceRecordSet
+				if ptr, err = r.findPTR(zone, name); err != nil {
+					return
+				}
+				if ptr != nil {
+					continue
+				}
+				message = fmt.Sprintf("no PTR record for %s", value)
+			}
+			findings = append(findings, &Finding{
+				Severity: SeverityWarning,
+				Check:    "ptr-missing",
+				Name:     zoneFileName(*rrs.Name),
+				Type:     *rrs.Type,
+				Message:  message,
+			})
+		}
+	}
+	inZones := func(name string) bool {
+		for _, zone := range zones {
+			apex := strings.ToLower(Fqdn(zone))
+			if name == apex || strings.HasSuffix(name, "."+apex) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, zone := range r.zones {
+		var list []*route53.ResourceRecordSet
+		if list, err = r.RecordSets(zone); err != nil {
+			return
+		}
+		for _, rrs := range list {
+			if *rrs.Type != "PTR" {
+				continue
+			}
+			for _, value := range recordSetValues(rrs) {
+				target := strings.ToLower(Fqdn(value))
+				if !inZones(target) || names[target] {
+					continue
+				}
+				findings = append(findings, &Finding{
+					Severity: SeverityWarning,
+					Check:    "ptr-dangling",
+					Name:     zoneFileName(*rrs.Name),
+					Type:     "PTR",
+					Message:  fmt.Sprintf("target %s doesn't exist", value),
+				})
+			}
+		}
+	}
+	sortFindings(findings)
+	return
+}
@@ -0,0 +1,204 @@
+package got
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+)
+
+// newPTRClient returns a Route53 fake with a forward zone and its reverse
+// zones, along with the record sets of the forward zone.
+func newPTRClient() (*mocks.Route53Client, []*route53.ResourceRecordSet) {
+	svc := mocks.NewRoute53Client()
+	forward := svc.AddHostedZone("example.com")
+	v4 := svc.AddHostedZone("0.10.in-addr.arpa")
+	v6 := svc.AddHostedZone("8.b.d.0.1.0.0.2.ip6.arpa")
+	svc.AddHostedZone("example.net")
+	svc.AddRecordSets(
+		forward,
+		newRecordSet("www.example.com.", "A", 60, "10.0.0.1"),
+		newRecordSet("api.example.com.", "A", 60, "10.0.0.3"),
+		newRecordSet("\\052.example.com.", "A", 60, "10.0.0.7"),
+	)
+	svc.AddRecordSets(
+		v4,
+		newRecordSet("1.0.0.10.in-addr.arpa.", "PTR", 300, "www.example.com."),
+		newRecordSet("2.0.0.10.in-addr.arpa.", "PTR", 300, "old.example.com."),
+		newRecordSet("3.0.0.10.in-addr.arpa.", "PTR", 300, "api.example.com.", "shared.example.com."),
+		newRecordSet("6.0.0.10.in-addr.arpa.", "PTR", 300, "host.example.net."),
+	)
+	svc.AddRecordSets(
+		v6,
+		newRecordSet(
+			"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.",
+			"PTR",
+			300,
+			"www.example.com.",
+		),
+	)
+	return svc, svc.RecordSets(forward)
+}
+
+func TestPTRChanges(t *testing.T) {
+	svc, current := newPTRClient()
+	r, err := NewReverseZones(nil, svc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	alias := &route53.ResourceRecordSet{
+		Name: aws.String("lb.example.com."),
+		Type: aws.String("A"),
+		AliasTarget: &route53.AliasTarget{
+			DNSName:      aws.String("lb.elb.amazonaws.com."),
+			HostedZoneId: aws.String("Z35SXDOTRQ7X7K"),
+		},
+	}
+	changes := []*route53.Change{
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet("WWW.example.com.", "A", 60, "10.0.0.2", "10.0.0.4"),
+		},
+		{
+			Action:            aws.String("DELETE"),
+			ResourceRecordSet: findRoutedRecordSet(current, "api.example.com.", "A", ""),
+		},
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet("v6.example.com.", "AAAA", 60, "2001:db8::1"),
+		},
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet("\\052.example.com.", "A", 60, "10.0.0.8"),
+		},
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: alias,
+		},
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet("mail.example.com.", "MX", 60, "10 mx.example.com."),
+		},
+	}
+	zoneChanges, err := r.PTRChanges(changes, current)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	out := []string{}
+	for _, zc := range zoneChanges {
+		if len(zc.Current) == 0 {
+			t.Errorf("Expected current record sets of %s", *zc.Zone.Name)
+		}
+		for _, change := range zc.Changes {
+			out = append(out, fmt.Sprintf(
+				"%s %s %s %d %s",
+				*zc.Zone.Name,
+				*change.Action,
+				*change.ResourceRecordSet.Name,
+				*change.ResourceRecordSet.TTL,
+				strings.Join(recordSetValues(change.ResourceRecordSet), ","),
+			))
+		}
+	}
+	expected := []string{
+		"0.10.in-addr.arpa. DELETE 1.0.0.10.in-addr.arpa. 300 www.example.com.",
+		"0.10.in-addr.arpa. UPSERT 2.0.0.10.in-addr.arpa. 300 www.example.com.",
+		"0.10.in-addr.arpa. UPSERT 4.0.0.10.in-addr.arpa. 60 www.example.com.",
+		"0.10.in-addr.arpa. UPSERT 3.0.0.10.in-addr.arpa. 300 shared.example.com.",
+		"8.b.d.0.1.0.0.2.ip6.arpa. UPSERT 1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa. 300 v6.example.com.",
+	}
+	if strings.Join(out, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected changes:\n%s", strings.Join(out, "\n"))
+	}
+
+	_, err = r.PTRChanges(
+		[]*route53.Change{
+			{
+				Action:            aws.String("UPSERT"),
+				ResourceRecordSet: newRecordSet("mail.example.com.", "A", 60, "192.168.0.1"),
+			},
+		},
+		current,
+	)
+	if err == nil || err.Error() != "no reverse zone for 192.168.0.1" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestPTRChangesWeighted(t *testing.T) {
+	svc := mocks.NewRoute53Client()
+	forward := svc.AddHostedZone("example.com")
+	v4 := svc.AddHostedZone("0.10.in-addr.arpa")
+	blue := newRecordSet("www.example.com.", "A", 60, "10.0.0.1", "10.0.0.2")
+	blue.SetIdentifier = aws.String("blue")
+	blue.Weight = aws.Int64(10)
+	green := newRecordSet("www.example.com.", "A", 60, "10.0.0.1")
+	green.SetIdentifier = aws.String("green")
+	green.Weight = aws.Int64(90)
+	svc.AddRecordSets(forward, blue, green)
+	svc.AddRecordSets(
+		v4,
+		newRecordSet("1.0.0.10.in-addr.arpa.", "PTR", 300, "www.example.com."),
+		newRecordSet("2.0.0.10.in-addr.arpa.", "PTR", 300, "www.example.com."),
+	)
+	r, err := NewReverseZones(nil, svc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	zoneChanges, err := r.PTRChanges(
+		[]*route53.Change{
+			{
+				Action:            aws.String("DELETE"),
+				ResourceRecordSet: blue,
+			},
+		},
+		svc.RecordSets(forward),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	out := []string{}
+	for _, zc := range zoneChanges {
+		for _, change := range zc.Changes {
+			out = append(out, *change.Action+" "+*change.ResourceRecordSet.Name)
+		}
+	}
+	// 10.0.0.1 is still served by green.
+	expected := []string{"DELETE 2.0.0.10.in-addr.arpa."}
+	if strings.Join(out, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected changes:\n%s", strings.Join(out, "\n"))
+	}
+}
+
+func TestReverseZonesAudit(t *testing.T) {
+	svc, current := newPTRClient()
+	r, err := NewReverseZones(nil, svc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	current = append(
+		current,
+		newRecordSet("db.example.com.", "A", 60, "10.0.0.9", "192.168.0.1"),
+	)
+	findings, err := r.Audit([]string{"example.com"}, current)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	out := []string{}
+	for _, f := range findings {
+		out = append(out, fmt.Sprintf("%s %s %s %s", f.Check, f.Name, f.Type, f.Message))
+	}
+	expected := []string{
+		"ptr-dangling 2.0.0.10.in-addr.arpa. PTR target old.example.com. doesn't exist",
+		"ptr-dangling 3.0.0.10.in-addr.arpa. PTR target shared.example.com. doesn't exist",
+		"ptr-missing db.example.com. A no PTR record for 10.0.0.9",
+		"ptr-missing db.example.com. A no reverse zone for 192.168.0.1",
+	}
+	if strings.Join(out, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected findings:\n%s", strings.Join(out, "\n"))
+	}
+}
@@ -0,0 +1,265 @@
+package got
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// Record is a simplified view of a record set, used to present it to users
+// or other tools.
+type Record struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	SetIdentifier string   `json:"set_identifier,omitempty"`
+	TTL           int64    `json:"ttl,omitempty"`
+	Values        []string `json:"values,omitempty"`
+	Alias         string   `json:"alias,omitempty"`
+}
+
+// NewRecord returns the Record view of a record set.
+func NewRecord(rrs *route53.ResourceRecordSet) Record {
+	record := Record{
+		Name:          *rrs.Name,
+		Type:          *rrs.Type,
+		SetIdentifier: aws.StringValue(rrs.SetIdentifier),
+		TTL:           aws.Int64Value(rrs.TTL),
+	}
+	if rrs.AliasTarget != nil {
+		record.Alias = aws.StringValue(rrs.AliasTarget.DNSName)
+	}
+	for _, rr := range rrs.ResourceRecords {
+		record.Values = append(record.Values, *rr.Value)
+	}
+	return record
+}
+
+// NameFilter returns elem if its name matches the filter, which may be a
+// shell pattern such as *.example.com. Patterns are matched label by
+// label, so * never matches a dot and *.example.com doesn't match
+// a.b.example.com, the same as a DNS wildcard. A name that is literally
+// the filter, such as the wildcard record *.example.com, always matches.
+func NameFilter(
+	elem *route53.ResourceRecordSet,
+	filter string,
+) *route53.ResourceRecordSet {
+	name := strings.ToLower(zoneFileName(*elem.Name))
+	pattern := strings.ToLower(Fqdn(filter))
+	if name == pattern {
+		return elem
+	}
+	labels := strings.Split(name, ".")
+	patterns := strings.Split(pattern, ".")
+	if len(labels) != len(patterns) {
+		return nil
+	}
+	for i := range labels {
+		if ok, err := path.Match(patterns[i], labels[i]); err != nil || !ok {
+			return nil
+		}
+	}
+	return elem
+}
+
+// ExactNameFilter returns elem if its name is the filter, without pattern
+// matching.
+func ExactNameFilter(
+	elem *route53.ResourceRecordSet,
+	filter string,
+) *route53.ResourceRecordSet {
+	if strings.EqualFold(zoneFileName(*elem.Name), Fqdn(filter)) {
+		return elem
+	}
+	return nil
+}
+
+// TypeFilter returns elem if its type is the filter.
+func TypeFilter(
+	elem *route53.ResourceRecordSet,
+	filter string,
+) *route53.ResourceRecordSet {
+	if strings.EqualFold(*elem.Type, filter) {
+		return elem
+	}
+	return nil
+}
+
+// SetIdentifierFilter returns elem if its set identifier is the filter.
+func SetIdentifierFilter(
+	elem *route53.ResourceRecordSet,
+	filter string,
+) *route53.ResourceRecordSet {
+	if aws.StringValue(elem.SetIdentifier) == filter {
+		return elem
+	}
+	return nil
+}
+
+// ValueFilter returns elem if any of its values, or its alias target,
+// contains the filter.
+func ValueFilter(
+	elem *route53.ResourceRecordSet,
+	filter string,
+) *route53.ResourceRecordSet {
+	for _, value := range NewRecord(elem).Values {
+		if strings.Contains(value, filter) {
+			return elem
+		}
+	}
+	if elem.AliasTarget != nil &&
+		strings.Contains(aws.StringValue(elem.AliasTarget.DNSName), filter) {
+		return elem
+	}
+	return nil
+}
+
+// TTLFilter returns elem if its TTL satisfies the filter, which is a number
+// optionally preceded by one of the =, <, >, <= or >= operators. Invalid
+// filters, rejected by ValidateTTLFilter, match nothing.
+func TTLFilter(
+	elem *route53.ResourceRecordSet,
+	filter string,
+) *route53.ResourceRecordSet {
+	if elem.TTL == nil {
+		return nil
+	}
+	operator, value, err := parseTTLFilter(filter)
+	if err != nil {
+		return nil
+	}
+	ttl := *elem.TTL
+	var ok bool
+	switch operator {
+	case "", "=", "==":
+		ok = ttl == value
+	case "<":
+		ok = ttl < value
+	case "<=":
+		ok = ttl <= value
+	case ">":
+		ok = ttl > value
+	case ">=":
+		ok = ttl >= value
+	}
+	if ok {
+		return elem
+	}
+	return nil
+}
+
+// ValidateTTLFilter returns an error if filter isn't valid for TTLFilter.
+func ValidateTTLFilter(filter string) error {
+	_, _, err := parseTTLFilter(filter)
+	return err
+}
+
+// parseTTLFilter splits a TTLFilter filter in its operator and value.
+func parseTTLFilter(filter string) (operator string, value int64, err error) {
+	operator = strings.TrimRight(filter, "0123456789 ")
+	number := strings.TrimSpace(strings.TrimPrefix(filter, operator))
+	operator = strings.TrimSpace(operator)
+	if value, err = strconv.ParseInt(number, 10, 64); err != nil {
+		err = fmt.Errorf("invalid TTL filter %s", filter)
+		return
+	}
+	switch operator {
+	case "", "=", "==", "<", "<=", ">", ">=":
+	default:
+		err = fmt.Errorf("unknown operator %s in TTL filter %s", operator, filter)
+	}
+	return
+}
+
+// SortResourceRecordSets sorts the list by name, type and set identifier.
+func SortResourceRecordSets(list []*route53.ResourceRecordSet) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return recordSetKey(list[i]) < recordSetKey(list[j])
+	})
+}
+
+// WriteTable writes the list as a table with a row per record set.
+func WriteTable(w io.Writer, list []*route53.ResourceRecordSet) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "NAME\tTYPE\tTTL\tVALUES"); err != nil {
+		return err
+	}
+	for _, rrs := range list {
+		if _, err := fmt.Fprintf(
+			tw,
+			"%s\t%s\t%s\t%s\n",
+			*rrs.Name,
+			*rrs.Type,
+			formatTTL(rrs),
+			strings.Join(formatValues(rrs), ", "),
+		); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the list as a JSON array of Records.
+func WriteJSON(w io.Writer, list []*route53.ResourceRecordSet) error {
+	records := []Record{}
+	for _, rrs := range list {
+		records = append(records, NewRecord(rrs))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// WriteCSV writes the list in CSV format, as read by ReadRecords, with a
+// row per value in the name, type, set_identifier, ttl and value columns.
+// Alias record sets take a single row with the DNS name of their target in
+// the alias column instead of a value.
+func WriteCSV(w io.Writer, list []*route53.ResourceRecordSet) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"name",
+		"type",
+		"set_identifier",
+		"ttl",
+		"value",
+		"alias",
+	}); err != nil {
+		return err
+	}
+	for _, rrs := range list {
+		row := []string{
+			*rrs.Name,
+			*rrs.Type,
+			aws.StringValue(rrs.SetIdentifier),
+			"",
+			"",
+			"",
+		}
+		if rrs.TTL != nil {
+			row[3] = strconv.FormatInt(*rrs.TTL, 10)
+		}
+		if rrs.AliasTarget != nil {
+			row[5] = aws.StringValue(rrs.AliasTarget.DNSName)
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, rr := range rrs.ResourceRecords {
+			row[4] = *rr.Value
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
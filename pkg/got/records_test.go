@@ -0,0 +1,150 @@
+package got
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var recordsList = []*route53.ResourceRecordSet{
+	newRecordSet("www.example.com.", "CNAME", 300, "lb.example.com."),
+	newRecordSet("api.example.com.", "A", 60, "10.0.0.1", "10.0.0.2"),
+	newRecordSet("\\052.example.com.", "A", 3600, "10.0.0.3"),
+	newRecordSet("api.example.com.", "AAAA", 60, "::1"),
+}
+
+var recordFilterTests = []struct {
+	name     string
+	filter   string
+	fn       func(*route53.ResourceRecordSet, string) *route53.ResourceRecordSet
+	expected int
+}{
+	{"exact name", "api.example.com", NameFilter, 2},
+	{"name pattern", "a*.example.com.", NameFilter, 2},
+	{"wildcard name", "\\*.example.com.", NameFilter, 1},
+	{"pattern within a label", "*.com", NameFilter, 0},
+	{"exact name only", "api.example.com", ExactNameFilter, 2},
+	{"no exact name pattern", "a*.example.com.", ExactNameFilter, 0},
+	{"exact wildcard name", "*.example.com.", ExactNameFilter, 1},
+	{"type", "a", TypeFilter, 2},
+	{"set identifier", "", SetIdentifierFilter, 4},
+	{"value", "10.0.0", ValueFilter, 2},
+	{"exact ttl", "60", TTLFilter, 2},
+	{"greater ttl", ">60", TTLFilter, 2},
+	{"lower or equal ttl", "<=300", TTLFilter, 3},
+	{"invalid ttl", ">abc", TTLFilter, 0},
+}
+
+func TestRecordFilters(t *testing.T) {
+	for _, tt := range recordFilterTests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := FilterResourceRecords(
+				recordsList,
+				[]string{tt.filter},
+				tt.fn,
+			)
+			if len(out) != tt.expected {
+				t.Errorf(
+					"Expected %d records, received %d",
+					tt.expected,
+					len(out),
+				)
+			}
+		})
+	}
+}
+
+func TestValidateTTLFilter(t *testing.T) {
+	for _, filter := range []string{"300", "= 300", "<60", ">= 3600"} {
+		if err := ValidateTTLFilter(filter); err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+	}
+	for filter, expected := range map[string]string{
+		"~300":  "unknown operator ~ in TTL filter ~300",
+		"=>300": "unknown operator => in TTL filter =>300",
+		">abc":  "invalid TTL filter >abc",
+		">":     "invalid TTL filter >",
+		"":      "invalid TTL filter ",
+	} {
+		if err := ValidateTTLFilter(filter); err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q, received %v", expected, err)
+		}
+	}
+}
+
+func TestNameFilterLabels(t *testing.T) {
+	deep := newRecordSet("a.b.example.com.", "A", 300, "10.0.0.1")
+	if NameFilter(deep, "*.example.com") != nil {
+		t.Errorf("Unexpected match of a.b.example.com. by *.example.com")
+	}
+	if NameFilter(deep, "*.*.example.com") == nil {
+		t.Errorf("Expected a.b.example.com. to match *.*.example.com")
+	}
+	wildcard := newRecordSet("\\052.b.example.com.", "A", 300, "10.0.0.1")
+	if NameFilter(wildcard, "*.b.example.com") == nil {
+		t.Errorf("Expected *.b.example.com. to match itself")
+	}
+}
+
+func TestSortResourceRecordSets(t *testing.T) {
+	list := append([]*route53.ResourceRecordSet{}, recordsList...)
+	SortResourceRecordSets(list)
+	expected := []string{
+		"\\052.example.com. A",
+		"api.example.com. A",
+		"api.example.com. AAAA",
+		"www.example.com. CNAME",
+	}
+	for i, rrs := range list {
+		if out := *rrs.Name + " " + *rrs.Type; out != expected[i] {
+			t.Errorf("Expected %s, received %s", expected[i], out)
+		}
+	}
+}
+
+var recordOutputTests = []struct {
+	name     string
+	fn       func(w *bytes.Buffer) error
+	expected string
+}{
+	{
+		"table",
+		func(w *bytes.Buffer) error { return WriteTable(w, recordsList[1:2]) },
+		"NAME             TYPE TTL VALUES\n" +
+			"api.example.com. A    60  10.0.0.1, 10.0.0.2\n",
+	},
+	{
+		"json",
+		func(w *bytes.Buffer) error { return WriteJSON(w, recordsList[:1]) },
+		"[\n  {\n    \"name\": \"www.example.com.\",\n" +
+			"    \"type\": \"CNAME\",\n    \"ttl\": 300,\n" +
+			"    \"values\": [\n      \"lb.example.com.\"\n    ]\n  }\n]\n",
+	},
+	{
+		"csv",
+		func(w *bytes.Buffer) error { return WriteCSV(w, recordsList[1:2]) },
+		"name,type,set_identifier,ttl,value,alias\n" +
+			"api.example.com.,A,,60,10.0.0.1,\n" +
+			"api.example.com.,A,,60,10.0.0.2,\n",
+	},
+}
+
+func TestRecordOutputs(t *testing.T) {
+	for _, tt := range recordOutputTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.fn(&out); err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf(
+					"Output doesn't match. Expected:\n%s\n-----\n%s",
+					tt.expected,
+					out.String(),
+				)
+			}
+		})
+	}
+}
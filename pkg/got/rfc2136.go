@@ -0,0 +1,167 @@
+package got
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/miekg/dns"
+)
+
+// tsigFudge is the time difference, in seconds, allowed between client and
+// server when signing with TSIG.
+const tsigFudge = 300
+
+// RFC2136Provider is the Provider for a zone in a DNS server accepting RFC
+// 2136 dynamic updates, such as BIND or Knot. Records are listed with a zone
+// transfer, and both operations are signed with TSIG if TSIGName is set.
+type RFC2136Provider struct {
+	Zone          string
+	Server        string
+	TSIGName      string
+	TSIGSecret    string
+	TSIGAlgorithm string
+}
+
+// NewRFC2136Provider returns the Provider for zone in server, which is an
+// address in host:port form. The TSIG key is only used if name is not empty,
+// and algorithm defaults to HMAC-SHA256.
+func NewRFC2136Provider(
+	zone string,
+	server string,
+	name string,
+	secret string,
+	algorithm string,
+) *RFC2136Provider {
+	if algorithm == "" {
+		algorithm = dns.HmacSHA256
+	}
+	if name != "" {
+		name = dns.Fqdn(name)
+	}
+	return &RFC2136Provider{
+		Zone:          dns.Fqdn(zone),
+		Server:        server,
+		TSIGName:      name,
+		TSIGSecret:    secret,
+		TSIGAlgorithm: dns.Fqdn(algorithm),
+	}
+}
+
+// sign adds the TSIG record to the message, returning the secrets to
+// verify the response, or nil if there is no key.
+func (p *RFC2136Provider) sign(m *dns.Msg) map[string]string {
+	if p.TSIGName == "" {
+		return nil
+	}
+	m.SetTsig(p.TSIGName, p.TSIGAlgorithm, tsigFudge, time.Now().Unix())
+	return map[string]string{p.TSIGName: p.TSIGSecret}
+}
+
+// List returns every record set in the zone, obtained with an AXFR.
+func (p *RFC2136Provider) List() (
+	records []*route53.ResourceRecordSet,
+	err error,
+) {
+	m := new(dns.Msg)
+	m.SetAxfr(p.Zone)
+	t := &dns.Transfer{TsigSecret: p.sign(m)}
+	envelopes, err := t.In(m, p.Server)
+	if err != nil {
+		return
+	}
+	list := []dns.RR{}
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, envelope.Error
+		}
+		list = append(list, envelope.RR...)
+	}
+	// The SOA record is repeated at the end of the transfer
+	if len(list) > 1 {
+		list = list[:len(list)-1]
+	}
+	records = recordSetsFromRRs(list)
+	return
+}
+
+// Apply performs all changes in a single dynamic update. CREATE changes
+// require the record set not to exist, as they do in Route53. Routing
+// policies and alias records aren't supported. Nothing is sent if any
+// change is invalid.
+func (p *RFC2136Provider) Apply(changes []*route53.Change) (ids []string, err error) {
+	if len(changes) <= 0 {
+		err = fmt.Errorf("no records to process")
+		return
+	}
+	for _, change := range changes {
+		if err = validateUpdate(change); err != nil {
+			return
+		}
+	}
+	m := new(dns.Msg)
+	m.SetUpdate(p.Zone)
+	for _, change := range changes {
+		var rrList []dns.RR
+		if rrList, err = rrsFromRecordSet(change.ResourceRecordSet); err != nil {
+			return
+		}
+		switch *change.Action {
+		case "CREATE":
+			m.RRsetNotUsed(rrList[:1])
+			m.Insert(rrList)
+		case "UPSERT":
+			m.RemoveRRset(rrList[:1])
+			m.Insert(rrList)
+		case "DELETE":
+			m.Remove(rrList)
+		}
+	}
+	c := &dns.Client{Net: "tcp", TsigSecret: p.sign(m)}
+	r, _, err := c.Exchange(m, p.Server)
+	if err != nil {
+		return
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		err = fmt.Errorf(
+			"update of %s failed: %s",
+			p.Zone,
+			dns.RcodeToString[r.Rcode],
+		)
+		return
+	}
+	ids = append(ids, strconv.FormatUint(uint64(m.Id), 10))
+	return
+}
+
+// validateUpdate returns an error if the change can't be part of a dynamic
+// update.
+func validateUpdate(change *route53.Change) error {
+	rrs := change.ResourceRecordSet
+	switch {
+	case !RoutingOf(rrs).IsSimple():
+		return fmt.Errorf(
+			"record %s %s uses features not supported by RFC 2136",
+			*rrs.Name,
+			*rrs.Type,
+		)
+	case len(rrs.ResourceRecords) == 0:
+		return fmt.Errorf("record %s %s has no values", *rrs.Name, *rrs.Type)
+	}
+	switch *change.Action {
+	case "CREATE", "UPSERT", "DELETE":
+		return nil
+	}
+	return fmt.Errorf("unknown action %s", *change.Action)
+}
+
+// Status always reports changes as applied, as dynamic updates are applied
+// synchronously.
+func (p *RFC2136Provider) Status(
+	ctx context.Context,
+	id string,
+) (done bool, status string, err error) {
+	return true, "applied", nil
+}
@@ -0,0 +1,290 @@
+package got
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/miekg/dns"
+)
+
+var tsigName = "test-key."
+var tsigSecret = "c2VjcmV0LWtleS1mb3ItdGVzdHMtb25seQ=="
+
+// authoritativeServer is a minimal DNS server holding a zone in memory. It
+// answers zone transfers and dynamic updates signed with TSIG, standing in
+// for BIND or Knot in tests.
+type authoritativeServer struct {
+	sync.Mutex
+	zone   string
+	rrs    []dns.RR
+	server *dns.Server
+	addr   string
+}
+
+func newAuthoritativeServer(
+	t *testing.T,
+	zone string,
+	contents string,
+) *authoritativeServer {
+	s := &authoritativeServer{zone: dns.Fqdn(zone)}
+	zp := dns.NewZoneParser(strings.NewReader(contents), s.zone, "")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		s.rrs = append(s.rrs, rr)
+	}
+	if err := zp.Err(); err != nil {
+		t.Fatalf("Invalid zone: %s", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	s.addr = listener.Addr().String()
+	started := make(chan bool)
+	s.server = &dns.Server{
+		Listener:          listener,
+		Handler:           s,
+		TsigSecret:        map[string]string{tsigName: tsigSecret},
+		NotifyStartedFunc: func() { close(started) },
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction {
+			return dns.MsgAccept
+		},
+	}
+	go func() {
+		if err := s.server.ActivateAndServe(); err != nil {
+			t.Logf("Server stopped: %s", err)
+		}
+	}()
+	<-started
+	t.Cleanup(func() {
+		if err := s.server.Shutdown(); err != nil {
+			t.Logf("Unable to stop server: %s", err)
+		}
+	})
+	return s
+}
+
+// ServeDNS answers AXFR and UPDATE requests.
+func (s *authoritativeServer) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	s.Lock()
+	defer s.Unlock()
+	m := new(dns.Msg)
+	m.SetReply(r)
+	switch {
+	case r.IsTsig() == nil || w.TsigStatus() != nil:
+		m.Rcode = dns.RcodeNotAuth
+	case r.Opcode == dns.OpcodeUpdate:
+		m.Rcode = s.update(r)
+	case r.Question[0].Qtype == dns.TypeAXFR:
+		m.Answer = append(m.Answer, s.rrs...)
+		m.Answer = append(m.Answer, s.rrs[0])
+	default:
+		m.Rcode = dns.RcodeNotImplemented
+	}
+	if r.IsTsig() != nil {
+		m.SetTsig(tsigName, dns.HmacSHA256, tsigFudge, time.Now().Unix())
+	}
+	if err := w.WriteMsg(m); err != nil {
+		panic(err)
+	}
+}
+
+// update applies the update in r, if its prerequisites are met.
+func (s *authoritativeServer) update(r *dns.Msg) int {
+	for _, prereq := range r.Answer {
+		header := prereq.Header()
+		if header.Class == dns.ClassNONE && s.exists(header) {
+			return dns.RcodeYXRrset
+		}
+	}
+	for _, rr := range r.Ns {
+		header := rr.Header()
+		switch header.Class {
+		case dns.ClassANY:
+			s.remove(func(old dns.RR) bool {
+				return sameRRset(old.Header(), header)
+			})
+		case dns.ClassNONE:
+			target := dns.Copy(rr)
+			target.Header().Class = dns.ClassINET
+			s.remove(func(old dns.RR) bool {
+				return dns.IsDuplicate(old, target)
+			})
+		default:
+			s.rrs = append(s.rrs, rr)
+		}
+	}
+	return dns.RcodeSuccess
+}
+
+func (s *authoritativeServer) exists(header *dns.RR_Header) bool {
+	for _, rr := range s.rrs {
+		if sameRRset(rr.Header(), header) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *authoritativeServer) remove(match func(dns.RR) bool) {
+	kept := []dns.RR{}
+	for _, rr := range s.rrs {
+		if !match(rr) {
+			kept = append(kept, rr)
+		}
+	}
+	s.rrs = kept
+}
+
+func sameRRset(a, b *dns.RR_Header) bool {
+	return strings.EqualFold(a.Name, b.Name) && a.Rrtype == b.Rrtype
+}
+
+var authoritativeZone = `$TTL 300
+@	IN	SOA	ns1 hostmaster 1 7200 900 1209600 86400
+@	IN	NS	ns1
+ns1	IN	A	10.0.0.53
+www	IN	A	10.0.0.1
+www	IN	A	10.0.0.2
+old	IN	CNAME	www
+`
+
+// listKeys returns name, type and values of every record set listed.
+func listKeys(t *testing.T, p Provider) (keys []string) {
+	records, err := p.List()
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	for _, rrs := range records {
+		keys = append(
+			keys,
+			*rrs.Name+" "+*rrs.Type+" "+strings.Join(NewRecord(rrs).Values, ","),
+		)
+	}
+	return
+}
+
+func TestRFC2136Provider(t *testing.T) {
+	s := newAuthoritativeServer(t, "example.com", authoritativeZone)
+	p := NewRFC2136Provider("example.com", s.addr, tsigName, tsigSecret, "")
+	expected := []string{
+		"example.com. SOA ns1.example.com. hostmaster.example.com. 1 7200 900 1209600 86400",
+		"example.com. NS ns1.example.com.",
+		"ns1.example.com. A 10.0.0.53",
+		"www.example.com. A 10.0.0.1,10.0.0.2",
+		"old.example.com. CNAME www.example.com.",
+	}
+	if keys := listKeys(t, p); strings.Join(keys, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected records:\n%s", strings.Join(keys, "\n"))
+	}
+
+	_, err := p.Apply([]*route53.Change{
+		{
+			Action:            aws.String("DELETE"),
+			ResourceRecordSet: newRecordSet("old.example.com.", "CNAME", 300, "www.example.com."),
+		},
+		{
+			Action:            aws.String("CREATE"),
+			ResourceRecordSet: newRecordSet("txt.example.com.", "TXT", 60, "\"caf\\351\""),
+		},
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet("www.example.com.", "A", 60, "10.0.0.3"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected = []string{
+		"example.com. SOA ns1.example.com. hostmaster.example.com. 1 7200 900 1209600 86400",
+		"example.com. NS ns1.example.com.",
+		"ns1.example.com. A 10.0.0.53",
+		"txt.example.com. TXT \"caf\\351\"",
+		"www.example.com. A 10.0.0.3",
+	}
+	if keys := listKeys(t, p); strings.Join(keys, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected records:\n%s", strings.Join(keys, "\n"))
+	}
+
+	_, err = p.Apply([]*route53.Change{
+		{
+			Action:            aws.String("CREATE"),
+			ResourceRecordSet: newRecordSet("www.example.com.", "A", 60, "10.0.0.4"),
+		},
+	})
+	if err == nil || err.Error() != "update of example.com. failed: YXRRSET" {
+		t.Errorf("Expected failure creating an existing record, received %v", err)
+	}
+}
+
+func TestRFC2136ProviderUnsigned(t *testing.T) {
+	s := newAuthoritativeServer(t, "example.com", authoritativeZone)
+	p := NewRFC2136Provider("example.com", s.addr, "", "", "")
+	_, err := p.Apply([]*route53.Change{
+		{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: newRecordSet("www.example.com.", "A", 60, "10.0.0.3"),
+		},
+	})
+	if err == nil || err.Error() != "update of example.com. failed: NOTAUTH" {
+		t.Errorf("Expected unsigned update to fail, received %v", err)
+	}
+}
+
+var invalidUpdateCases = []struct {
+	name     string
+	change   *route53.Change
+	expected string
+}{
+	{
+		name: "No values",
+		change: &route53.Change{
+			Action: aws.String("UPSERT"),
+			ResourceRecordSet: &route53.ResourceRecordSet{
+				Name: aws.String("www.example.com."),
+				Type: aws.String("A"),
+				TTL:  aws.Int64(60),
+			},
+		},
+		expected: "record www.example.com. A has no values",
+	},
+	{
+		name: "Weighted",
+		change: &route53.Change{
+			Action:            aws.String("UPSERT"),
+			ResourceRecordSet: routingList[0],
+		},
+		expected: "record www.example.com. A uses features not supported by RFC 2136",
+	},
+	{
+		name: "Unknown action",
+		change: &route53.Change{
+			Action:            aws.String("REPLACE"),
+			ResourceRecordSet: newRecordSet("www.example.com.", "A", 60, "10.0.0.4"),
+		},
+		expected: "unknown action REPLACE",
+	},
+}
+
+func TestRFC2136ProviderInvalidChanges(t *testing.T) {
+	// No server is listening, as nothing is sent.
+	p := NewRFC2136Provider("example.com", "127.0.0.1:1", "", "", "")
+	for _, tc := range invalidUpdateCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.Apply([]*route53.Change{
+				{
+					Action:            aws.String("CREATE"),
+					ResourceRecordSet: newRecordSet("new.example.com.", "A", 60, "10.0.0.5"),
+				},
+				tc.change,
+			})
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("Expected %q, received %v", tc.expected, err)
+			}
+		})
+	}
+}
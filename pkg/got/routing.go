@@ -0,0 +1,97 @@
+package got
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// Routing holds the attributes of record sets not using the simple routing
+// policy, or pointing to an alias target instead of having values. Record
+// sets sharing name and type are told apart by their SetIdentifier.
+type Routing struct {
+	SetIdentifier string
+	Weight        *int64
+	Region        string
+	Failover      string
+	GeoLocation   *route53.GeoLocation
+	HealthCheckID string
+	AliasTarget   *route53.AliasTarget
+}
+
+// RoutingOf returns the Routing of a record set, or nil if it has none.
+func RoutingOf(rrs *route53.ResourceRecordSet) *Routing {
+	routing := &Routing{
+		SetIdentifier: aws.StringValue(rrs.SetIdentifier),
+		Weight:        rrs.Weight,
+		Region:        aws.StringValue(rrs.Region),
+		Failover:      aws.StringValue(rrs.Failover),
+		GeoLocation:   rrs.GeoLocation,
+		HealthCheckID: aws.StringValue(rrs.HealthCheckId),
+		AliasTarget:   rrs.AliasTarget,
+	}
+	if routing.IsSimple() {
+		return nil
+	}
+	return routing
+}
+
+// IsSimple returns true if the Routing has no attributes set.
+func (r *Routing) IsSimple() bool {
+	return r == nil ||
+		(r.SetIdentifier == "" &&
+			r.Weight == nil &&
+			r.Region == "" &&
+			r.Failover == "" &&
+			r.GeoLocation == nil &&
+			r.HealthCheckID == "" &&
+			r.AliasTarget == nil)
+}
+
+// apply sets the Routing attributes in the record set. Alias record sets
+// have neither TTL nor values, so these are removed.
+func (r *Routing) apply(rrs *route53.ResourceRecordSet) {
+	if r == nil {
+		return
+	}
+	if r.SetIdentifier != "" {
+		rrs.SetIdentifier = aws.String(r.SetIdentifier)
+	}
+	rrs.Weight = r.Weight
+	if r.Region != "" {
+		rrs.Region = aws.String(r.Region)
+	}
+	if r.Failover != "" {
+		rrs.Failover = aws.String(strings.ToUpper(r.Failover))
+	}
+	rrs.GeoLocation = r.GeoLocation
+	if r.HealthCheckID != "" {
+		rrs.HealthCheckId = aws.String(r.HealthCheckID)
+	}
+	if r.AliasTarget != nil {
+		rrs.AliasTarget = r.AliasTarget
+		rrs.TTL = nil
+		rrs.ResourceRecords = nil
+	}
+}
+
+// routingEqual returns true if both record sets have the same routing
+// attributes, other than their alias targets.
+func routingEqual(a, b *route53.ResourceRecordSet) bool {
+	return aws.StringValue(a.SetIdentifier) == aws.StringValue(b.SetIdentifier) &&
+		aws.Int64Value(a.Weight) == aws.Int64Value(b.Weight) &&
+		aws.StringValue(a.Region) == aws.StringValue(b.Region) &&
+		aws.StringValue(a.Failover) == aws.StringValue(b.Failover) &&
+		aws.StringValue(a.HealthCheckId) == aws.StringValue(b.HealthCheckId) &&
+		geoLocationEqual(a.GeoLocation, b.GeoLocation)
+}
+
+func geoLocationEqual(a, b *route53.GeoLocation) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return aws.StringValue(a.ContinentCode) == aws.StringValue(b.ContinentCode) &&
+		aws.StringValue(a.CountryCode) == aws.StringValue(b.CountryCode) &&
+		aws.StringValue(a.SubdivisionCode) == aws.StringValue(b.SubdivisionCode)
+}
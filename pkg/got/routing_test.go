@@ -0,0 +1,147 @@
+package got
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func TestUpsertChangeListRouting(t *testing.T) {
+	weighted := UpsertChangeList(
+		NewResourceRecordList([]string{"10.0.0.1"}),
+		60,
+		"www.example.com.",
+		"A",
+		&Routing{
+			SetIdentifier: "blue",
+			Weight:        aws.Int64(10),
+			HealthCheckID: "abcdef",
+		},
+	)[0].ResourceRecordSet
+	if aws.StringValue(weighted.SetIdentifier) != "blue" ||
+		aws.Int64Value(weighted.Weight) != 10 ||
+		aws.StringValue(weighted.HealthCheckId) != "abcdef" ||
+		aws.Int64Value(weighted.TTL) != 60 ||
+		len(weighted.ResourceRecords) != 1 {
+		t.Errorf("Unexpected weighted record set %v", weighted)
+	}
+
+	alias := UpsertChangeList(
+		NewResourceRecordList([]string{"10.0.0.1"}),
+		60,
+		"example.com.",
+		"A",
+		&Routing{
+			Failover: "primary",
+			AliasTarget: &route53.AliasTarget{
+				DNSName:              aws.String("lb.elb.amazonaws.com."),
+				HostedZoneId:         aws.String("Z35SXDOTRQ7X7K"),
+				EvaluateTargetHealth: aws.Bool(true),
+			},
+		},
+	)[0].ResourceRecordSet
+	if alias.TTL != nil || alias.ResourceRecords != nil {
+		t.Errorf("Alias record set shouldn't have TTL nor values: %v", alias)
+	}
+	if aws.StringValue(alias.Failover) != "PRIMARY" {
+		t.Errorf("Unexpected failover %v", alias.Failover)
+	}
+	if err := (&route53.Change{
+		Action:            aws.String("UPSERT"),
+		ResourceRecordSet: alias,
+	}).Validate(); err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+}
+
+var routingList = []*route53.ResourceRecordSet{
+	{
+		Name:            aws.String("www.example.com."),
+		Type:            aws.String("A"),
+		SetIdentifier:   aws.String("blue"),
+		Weight:          aws.Int64(10),
+		TTL:             aws.Int64(60),
+		ResourceRecords: NewResourceRecordList([]string{"10.0.0.1"}),
+	},
+	{
+		Name:            aws.String("www.example.com."),
+		Type:            aws.String("A"),
+		SetIdentifier:   aws.String("green"),
+		Weight:          aws.Int64(90),
+		TTL:             aws.Int64(60),
+		ResourceRecords: NewResourceRecordList([]string{"10.0.0.2"}),
+	},
+}
+
+func TestDeleteChangeListSetIdentifier(t *testing.T) {
+	for _, rrs := range routingList {
+		res, err := DeleteChangeList(
+			[]string{"www.example.com."},
+			"A",
+			*rrs.SetIdentifier,
+			routingList,
+		)
+		if err != nil || len(res) != 1 || res[0].ResourceRecordSet != rrs {
+			t.Errorf("Expected deletion of %v, received %v, %v", rrs, res, err)
+		}
+	}
+}
+
+func TestDeleteChangeListMissingSetIdentifier(t *testing.T) {
+	_, err := DeleteChangeList(
+		[]string{"www.example.com."},
+		"A",
+		"red",
+		routingList,
+	)
+	expected := "A record www.example.com. with set identifier red not found"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, received %v", expected, err)
+	}
+}
+
+func TestDiffRecordSetsRouting(t *testing.T) {
+	desired := []*route53.ResourceRecordSet{
+		routingList[0],
+		{
+			Name:            aws.String("www.example.com."),
+			Type:            aws.String("A"),
+			SetIdentifier:   aws.String("green"),
+			Weight:          aws.Int64(0),
+			TTL:             aws.Int64(60),
+			ResourceRecords: NewResourceRecordList([]string{"10.0.0.2"}),
+		},
+	}
+	changes := DiffRecordSets(routingList, desired, true)
+	if len(changes) != 1 ||
+		*changes[0].Action != "UPSERT" ||
+		*changes[0].ResourceRecordSet.SetIdentifier != "green" {
+		t.Errorf("Expected an UPSERT of green, received %v", changes)
+	}
+}
+
+func TestUpsertResourceRecordSetTTLKeepsRouting(t *testing.T) {
+	mockSvc := &mockRoute53Client{}
+	_, err := UpsertResourceRecordSetTTL(
+		routingList,
+		300,
+		aws.String("test"),
+		mockSvc,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	changes := mockSvc.changes
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, received %d", len(changes))
+	}
+	for i, change := range changes {
+		rrs := change.ResourceRecordSet
+		if *rrs.TTL != 300 ||
+			*rrs.SetIdentifier != *routingList[i].SetIdentifier ||
+			*rrs.Weight != *routingList[i].Weight {
+			t.Errorf("Unexpected change %v", change)
+		}
+	}
+}
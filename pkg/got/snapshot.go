@@ -0,0 +1,312 @@
+package got
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"gopkg.in/yaml.v2"
+)
+
+// Kinds of drift between a snapshot and the live zone.
+const (
+	// DriftAdded is a record set in the zone missing from the snapshot.
+	DriftAdded = "added"
+	// DriftRemoved is a record set in the snapshot missing from the zone.
+	DriftRemoved = "removed"
+	// DriftChanged is a record set in both with different data.
+	DriftChanged = "changed"
+)
+
+// Snapshot is the state of every record set of a zone at some point, other
+// than its authority records, meant to be committed and compared with the
+// zone later on. Unlike a Spec, it keeps the routing policies and alias
+// targets of the record sets.
+type Snapshot struct {
+	Zone    string           `yaml:"zone"`
+	Records []SnapshotRecord `yaml:"records"`
+}
+
+// SnapshotRecord is a record set in a Snapshot. Names are fully qualified,
+// in master file syntax.
+type SnapshotRecord struct {
+	Name           string         `yaml:"name"`
+	Type           string         `yaml:"type"`
+	SetIdentifier  string         `yaml:"set_identifier,omitempty"`
+	TTL            int64          `yaml:"ttl,omitempty"`
+	Values         []string       `yaml:"values,omitempty"`
+	Weight         *int64         `yaml:"weight,omitempty"`
+	Region         string         `yaml:"region,omitempty"`
+	Failover       string         `yaml:"failover,omitempty"`
+	GeoContinent   string         `yaml:"geo_continent,omitempty"`
+	GeoCountry     string         `yaml:"geo_country,omitempty"`
+	GeoSubdivision string         `yaml:"geo_subdivision,omitempty"`
+	HealthCheckID  string         `yaml:"health_check_id,omitempty"`
+	Alias          *SnapshotAlias `yaml:"alias,omitempty"`
+}
+
+// SnapshotAlias is the alias target of a SnapshotRecord.
+type SnapshotAlias struct {
+	Target               string `yaml:"target"`
+	ZoneID               string `yaml:"zone_id"`
+	EvaluateTargetHealth bool   `yaml:"evaluate_target_health,omitempty"`
+}
+
+// Drift is a difference between a record set in a snapshot and in the live
+// zone. Before is nil for record sets added, and After for those removed.
+type Drift struct {
+	Kind   string
+	Before *SnapshotRecord
+	After  *SnapshotRecord
+}
+
+// NewSnapshot returns the Snapshot of the record sets of zone. Record sets
+// are sorted by name, type and set identifier, and values are sorted, so
+// snapshots of the same records are always the same.
+func NewSnapshot(
+	zone string,
+	records []*route53.ResourceRecordSet,
+) *Snapshot {
+	list := RemoveZoneAuthority(records, zone)
+	SortResourceRecordSets(list)
+	s := &Snapshot{Zone: Fqdn(zone), Records: []SnapshotRecord{}}
+	for _, rrs := range list {
+		s.Records = append(s.Records, newSnapshotRecord(rrs))
+	}
+	return s
+}
+
+func newSnapshotRecord(rrs *route53.ResourceRecordSet) SnapshotRecord {
+	rec := SnapshotRecord{
+		Name:          zoneFileName(*rrs.Name),
+		Type:          *rrs.Type,
+		SetIdentifier: aws.StringValue(rrs.SetIdentifier),
+		TTL:           aws.Int64Value(rrs.TTL),
+		Weight:        rrs.Weight,
+		Region:        aws.StringValue(rrs.Region),
+		Failover:      aws.StringValue(rrs.Failover),
+		HealthCheckID: aws.StringValue(rrs.HealthCheckId),
+	}
+	for _, rr := range rrs.ResourceRecords {
+		rec.Values = append(rec.Values, *rr.Value)
+	}
+	sort.Strings(rec.Values)
+	if geo := rrs.GeoLocation; geo != nil {
+		rec.GeoContinent = aws.StringValue(geo.ContinentCode)
+		rec.GeoCountry = aws.StringValue(geo.CountryCode)
+		rec.GeoSubdivision = aws.StringValue(geo.SubdivisionCode)
+	}
+	if target := rrs.AliasTarget; target != nil {
+		rec.Alias = &SnapshotAlias{
+			Target:               aws.StringValue(target.DNSName),
+			ZoneID:               aws.StringValue(target.HostedZoneId),
+			EvaluateTargetHealth: aws.BoolValue(target.EvaluateTargetHealth),
+		}
+	}
+	return rec
+}
+
+// LoadSnapshot reads a Snapshot in YAML format.
+func LoadSnapshot(r io.Reader) (s *Snapshot, err error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	s = &Snapshot{}
+	if err = yaml.UnmarshalStrict(content, s); err != nil {
+		return nil, err
+	}
+	if s.Zone == "" {
+		return nil, fmt.Errorf("no zone specified")
+	}
+	return
+}
+
+// Write writes the Snapshot in YAML format.
+func (s *Snapshot) Write(w io.Writer) error {
+	content, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// RecordSets returns the record sets in the Snapshot.
+func (s *Snapshot) RecordSets() (records []*route53.ResourceRecordSet) {
+	for _, rec := range s.Records {
+		records = append(records, rec.recordSet())
+	}
+	return
+}
+
+func (rec *SnapshotRecord) recordSet() *route53.ResourceRecordSet {
+	rrs := &route53.ResourceRecordSet{
+		Name:            aws.String(route53Name(rec.Name)),
+		Type:            aws.String(rec.Type),
+		ResourceRecords: NewResourceRecordList(rec.Values),
+		Weight:          rec.Weight,
+	}
+	if rec.TTL > 0 {
+		rrs.TTL = aws.Int64(rec.TTL)
+	}
+	if rec.SetIdentifier != "" {
+		rrs.SetIdentifier = aws.String(rec.SetIdentifier)
+	}
+	if rec.Region != "" {
+		rrs.Region = aws.String(rec.Region)
+	}
+	if rec.Failover != "" {
+		rrs.Failover = aws.String(rec.Failover)
+	}
+	if rec.HealthCheckID != "" {
+		rrs.HealthCheckId = aws.String(rec.HealthCheckID)
+	}
+	if rec.GeoContinent != "" || rec.GeoCountry != "" || rec.GeoSubdivision != "" {
+		rrs.GeoLocation = &route53.GeoLocation{}
+		if rec.GeoContinent != "" {
+			rrs.GeoLocation.ContinentCode = aws.String(rec.GeoContinent)
+		}
+		if rec.GeoCountry != "" {
+			rrs.GeoLocation.CountryCode = aws.String(rec.GeoCountry)
+		}
+		if rec.GeoSubdivision != "" {
+			rrs.GeoLocation.SubdivisionCode = aws.String(rec.GeoSubdivision)
+		}
+	}
+	if rec.Alias != nil {
+		rrs.AliasTarget = &route53.AliasTarget{
+			DNSName:              aws.String(rec.Alias.Target),
+			HostedZoneId:         aws.String(rec.Alias.ZoneID),
+			EvaluateTargetHealth: aws.Bool(rec.Alias.EvaluateTargetHealth),
+		}
+	}
+	return rrs
+}
+
+// Drift returns the differences between the Snapshot and the live record
+// sets of the zone, in the order of the snapshot, followed by those added.
+func (s *Snapshot) Drift(live []*route53.ResourceRecordSet) (drifts []*Drift) {
+	current := NewSnapshot(s.Zone, live)
+	existing := map[string]*route53.ResourceRecordSet{}
+	records := map[string]*SnapshotRecord{}
+	for i, rrs := range current.RecordSets() {
+		key := recordSetKey(rrs)
+		existing[key] = rrs
+		records[key] = &current.Records[i]
+	}
+	seen := map[string]bool{}
+	for i := range s.Records {
+		rec := &s.Records[i]
+		rrs := rec.recordSet()
+		key := recordSetKey(rrs)
+		seen[key] = true
+		old, found := existing[key]
+		switch {
+		case !found:
+			drifts = append(drifts, &Drift{Kind: DriftRemoved, Before: rec})
+		case !recordSetEqual(rrs, old):
+			drifts = append(drifts, &Drift{
+				Kind:   DriftChanged,
+				Before: rec,
+				After:  records[key],
+			})
+		}
+	}
+	for i, rrs := range current.RecordSets() {
+		if !seen[recordSetKey(rrs)] {
+			drifts = append(drifts, &Drift{
+				Kind:  DriftAdded,
+				After: &current.Records[i],
+			})
+		}
+	}
+	return
+}
+
+// WriteDrift writes the drifts in unified format, the snapshot being the
+// original version and the live zone the new one, followed by a summary.
+// Lines are colorized with ANSI escape sequences if color is set.
+func WriteDrift(w io.Writer, drifts []*Drift, color bool) error {
+	counts := map[string]int{}
+	for _, d := range drifts {
+		counts[d.Kind]++
+		rec := d.After
+		if rec == nil {
+			rec = d.Before
+		}
+		header := fmt.Sprintf("%s %s", rec.Name, rec.Type)
+		if rec.SetIdentifier != "" {
+			header += " " + rec.SetIdentifier
+		}
+		if err := writeDiff(
+			w,
+			header,
+			strings.ToUpper(d.Kind),
+			d.Before.lines(),
+			d.After.lines(),
+			color,
+		); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(
+		w,
+		"%d added, %d removed, %d changed\n",
+		counts[DriftAdded],
+		counts[DriftRemoved],
+		counts[DriftChanged],
+	)
+	return err
+}
+
+// lines returns a line per value of the record, in master file syntax,
+// followed by a comment line per routing attribute. A nil record has no
+// lines.
+func (rec *SnapshotRecord) lines() (lines []string) {
+	if rec == nil {
+		return
+	}
+	ttl := "-"
+	if rec.TTL > 0 {
+		ttl = fmt.Sprint(rec.TTL)
+	}
+	line := func(value string) {
+		lines = append(
+			lines,
+			fmt.Sprintf("%s\t%s\t%s\t%s", rec.Name, ttl, rec.Type, value),
+		)
+	}
+	for _, value := range rec.Values {
+		line(value)
+	}
+	if rec.Alias != nil {
+		line(fmt.Sprintf(
+			"ALIAS %s %s evaluate_target_health=%t",
+			rec.Alias.Target,
+			rec.Alias.ZoneID,
+			rec.Alias.EvaluateTargetHealth,
+		))
+	}
+	attributes := []struct{ name, value string }{
+		{"region", rec.Region},
+		{"failover", rec.Failover},
+		{"geo_continent", rec.GeoContinent},
+		{"geo_country", rec.GeoCountry},
+		{"geo_subdivision", rec.GeoSubdivision},
+		{"health_check_id", rec.HealthCheckID},
+	}
+	if rec.Weight != nil {
+		line(fmt.Sprintf("; weight=%d", *rec.Weight))
+	}
+	for _, attribute := range attributes {
+		if attribute.value != "" {
+			line(fmt.Sprintf("; %s=%s", attribute.name, attribute.value))
+		}
+	}
+	return
+}
@@ -0,0 +1,151 @@
+package got
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func snapshotRecords() []*route53.ResourceRecordSet {
+	blue := newRecordSet("api.example.com.", "A", 60, "10.0.0.1")
+	blue.SetIdentifier = aws.String("blue")
+	blue.Weight = aws.Int64(90)
+	green := newRecordSet("api.example.com.", "A", 60, "10.0.0.2")
+	green.SetIdentifier = aws.String("green")
+	green.Weight = aws.Int64(10)
+	apex := &route53.ResourceRecordSet{
+		Name: aws.String("example.com."),
+		Type: aws.String("A"),
+		AliasTarget: &route53.AliasTarget{
+			DNSName:      aws.String("lb.elb.amazonaws.com."),
+			HostedZoneId: aws.String("Z35SXDOTRQ7X7K"),
+		},
+	}
+	return []*route53.ResourceRecordSet{
+		newRecordSet("www.example.com.", "A", 300, "10.0.0.4", "10.0.0.3"),
+		newRecordSet("example.com.", "SOA", 900, "ns-1.example.net. admin.example.com. 1 7200 900 1209600 86400"),
+		newRecordSet("example.com.", "NS", 172800, "ns-1.example.net."),
+		green,
+		newRecordSet("\\052.example.com.", "CNAME", 300, "www.example.com."),
+		blue,
+		apex,
+	}
+}
+
+const snapshotYAML = `zone: example.com.
+records:
+- name: '*.example.com.'
+  type: CNAME
+  ttl: 300
+  values:
+  - www.example.com.
+- name: api.example.com.
+  type: A
+  set_identifier: blue
+  ttl: 60
+  values:
+  - 10.0.0.1
+  weight: 90
+- name: api.example.com.
+  type: A
+  set_identifier: green
+  ttl: 60
+  values:
+  - 10.0.0.2
+  weight: 10
+- name: example.com.
+  type: A
+  alias:
+    target: lb.elb.amazonaws.com.
+    zone_id: Z35SXDOTRQ7X7K
+- name: www.example.com.
+  type: A
+  ttl: 300
+  values:
+  - 10.0.0.3
+  - 10.0.0.4
+`
+
+func TestSnapshotWrite(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := NewSnapshot("example.com", snapshotRecords()).Write(buf); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if buf.String() != snapshotYAML {
+		t.Errorf("Unexpected snapshot:\n%s", buf.String())
+	}
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	s, err := LoadSnapshot(strings.NewReader(snapshotYAML))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if drifts := s.Drift(snapshotRecords()); len(drifts) != 0 {
+		t.Errorf("Expected no drift, received %v", drifts)
+	}
+	if _, err = LoadSnapshot(strings.NewReader("records: []\n")); err == nil {
+		t.Error("Expected error loading snapshot without zone")
+	}
+	if _, err = LoadSnapshot(strings.NewReader("zone: a.\nfoo: 1\n")); err == nil {
+		t.Error("Expected error loading snapshot with unknown fields")
+	}
+}
+
+func TestSnapshotDrift(t *testing.T) {
+	s := NewSnapshot("example.com", snapshotRecords())
+	live := snapshotRecords()
+	// www changes a value, green changes weight, the wildcard is removed
+	// and mail is added.
+	live[0] = newRecordSet("www.example.com.", "A", 300, "10.0.0.3", "10.0.0.5")
+	live[3].Weight = aws.Int64(50)
+	live[4] = newRecordSet("mail.example.com.", "MX", 300, "10 mx.example.com.")
+
+	drifts := s.Drift(live)
+	kinds := []string{}
+	for _, d := range drifts {
+		kinds = append(kinds, d.Kind+" "+driftName(d.Before)+driftName(d.After))
+	}
+	expected := []string{
+		"removed *.example.com.",
+		"changed api.example.com.api.example.com.",
+		"changed www.example.com.www.example.com.",
+		"added mail.example.com.",
+	}
+	if strings.Join(kinds, "|") != strings.Join(expected, "|") {
+		t.Errorf("Unexpected drifts %v", kinds)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteDrift(buf, drifts[1:3], false); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	out := `--- api.example.com. A green
++++ api.example.com. A green
+@@ CHANGED @@
+ api.example.com.	60	A	10.0.0.2
+-api.example.com.	60	A	; weight=10
++api.example.com.	60	A	; weight=50
+--- www.example.com. A
++++ www.example.com. A
+@@ CHANGED @@
+ www.example.com.	300	A	10.0.0.3
+-www.example.com.	300	A	10.0.0.4
++www.example.com.	300	A	10.0.0.5
+0 added, 0 removed, 2 changed
+`
+	if buf.String() != out {
+		t.Errorf("Unexpected drift output:\n%s", buf.String())
+	}
+}
+
+// driftName returns the name of the record, or nothing if it's nil.
+func driftName(rec *SnapshotRecord) string {
+	if rec == nil {
+		return ""
+	}
+	return rec.Name
+}
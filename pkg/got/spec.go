@@ -0,0 +1,191 @@
+package got
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec describes the desired state of a DNS zone, so it can be reviewed as
+// any other configuration before being applied.
+type Spec struct {
+	Zone    string       `yaml:"zone"`
+	TTL     int64        `yaml:"ttl,omitempty"`
+	Prune   bool         `yaml:"prune,omitempty"`
+	Records []SpecRecord `yaml:"records"`
+}
+
+// SpecRecord describes a record set in a Spec. Names may be relative to the
+// zone, with @ standing for its apex, or fully qualified if ending in a dot.
+// Records without TTL get the one of the Spec.
+type SpecRecord struct {
+	Name   string   `yaml:"name"`
+	Type   string   `yaml:"type"`
+	TTL    int64    `yaml:"ttl,omitempty"`
+	Values []string `yaml:"values"`
+}
+
+// LoadSpec reads and validates a Spec in YAML format.
+func LoadSpec(r io.Reader) (spec *Spec, err error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	spec = &Spec{}
+	if err = yaml.UnmarshalStrict(content, spec); err != nil {
+		return nil, err
+	}
+	if err = spec.Validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Validate checks the Spec is complete and has no duplicated record sets.
+func (s *Spec) Validate() error {
+	if s.Zone == "" {
+		return fmt.Errorf("no zone specified")
+	}
+	seen := map[string]bool{}
+	for i, rec := range s.Records {
+		switch {
+		case rec.Name == "":
+			return fmt.Errorf("record %d has no name", i)
+		case rec.Type == "":
+			return fmt.Errorf("record %s has no type", rec.Name)
+		case len(rec.Values) == 0:
+			return fmt.Errorf("record %s %s has no values", rec.Name, rec.Type)
+		case rec.TTL <= 0 && s.TTL <= 0:
+			return fmt.Errorf("record %s %s has no TTL", rec.Name, rec.Type)
+		}
+		key := recordSetKey(s.recordSet(rec))
+		if seen[key] {
+			return fmt.Errorf("record %s %s is duplicated", rec.Name, rec.Type)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// RecordSets returns the record sets described by the Spec.
+func (s *Spec) RecordSets() (records []*route53.ResourceRecordSet) {
+	for _, rec := range s.Records {
+		records = append(records, s.recordSet(rec))
+	}
+	return
+}
+
+// Changes returns the changes needed for the current record sets of the zone
+// to match the Spec. Records not in the Spec are only deleted if the Spec
+// prunes, or prune is true. The authority records of the zone, and alias
+// records and those with routing policies, which the Spec can't describe,
+// are never deleted.
+func (s *Spec) Changes(
+	current []*route53.ResourceRecordSet,
+	prune bool,
+) []*route53.Change {
+	return DiffRecordSets(
+		s.managed(current),
+		RemoveZoneAuthority(s.RecordSets(), s.Zone),
+		prune || s.Prune,
+	)
+}
+
+// Conflicts returns an error per record set of the Spec that Route53 would
+// reject when applying the Changes for the current record sets, either
+// because an alias record set or record sets with routing policies have
+// its name and type, or because it shares its name with a CNAME record set,
+// or is one, and record sets of other types are left in the zone.
+func (s *Spec) Conflicts(
+	current []*route53.ResourceRecordSet,
+	prune bool,
+) (errs []error) {
+	desired := RemoveZoneAuthority(s.RecordSets(), s.Zone)
+	wanted := map[string]bool{}
+	for _, rrs := range desired {
+		wanted[recordSetKey(rrs)] = true
+	}
+	pruned := map[*route53.ResourceRecordSet]bool{}
+	if prune || s.Prune {
+		for _, rrs := range s.managed(current) {
+			pruned[rrs] = !wanted[recordSetKey(rrs)]
+		}
+	}
+	remaining := map[string][]*route53.ResourceRecordSet{}
+	for _, rrs := range current {
+		if !pruned[rrs] {
+			name := strings.ToLower(zoneFileName(*rrs.Name))
+			remaining[name] = append(remaining[name], rrs)
+		}
+	}
+	for _, rrs := range desired {
+		name := strings.ToLower(zoneFileName(*rrs.Name))
+		for _, other := range remaining[name] {
+			var err error
+			switch {
+			case *other.Type == *rrs.Type && !RoutingOf(other).IsSimple():
+				err = fmt.Errorf(
+					"record %s %s conflicts with an alias or routing policy",
+					*rrs.Name,
+					*rrs.Type,
+				)
+			case *other.Type != *rrs.Type &&
+				(*other.Type == "CNAME" || *rrs.Type == "CNAME"):
+				err = fmt.Errorf(
+					"record %s %s conflicts with the %s record of the name",
+					*rrs.Name,
+					*rrs.Type,
+					*other.Type,
+				)
+			}
+			if err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+	return
+}
+
+// managed returns the current record sets the Spec can describe, those
+// with the simple routing policy other than the authority records.
+func (s *Spec) managed(
+	current []*route53.ResourceRecordSet,
+) (managed []*route53.ResourceRecordSet) {
+	for _, rrs := range RemoveZoneAuthority(current, s.Zone) {
+		if RoutingOf(rrs).IsSimple() {
+			managed = append(managed, rrs)
+		}
+	}
+	return
+}
+
+func (s *Spec) recordSet(rec SpecRecord) *route53.ResourceRecordSet {
+	ttl := rec.TTL
+	if ttl <= 0 {
+		ttl = s.TTL
+	}
+	return &route53.ResourceRecordSet{
+		Name:            aws.String(absoluteName(rec.Name, s.Zone)),
+		Type:            aws.String(strings.ToUpper(rec.Type)),
+		TTL:             aws.Int64(ttl),
+		ResourceRecords: NewResourceRecordList(rec.Values),
+	}
+}
+
+// absoluteName returns the fully qualified version of a name relative to
+// zone, with @ standing for its apex, unless it's already fully qualified.
+func absoluteName(name, zone string) string {
+	switch {
+	case name == "@":
+		return Fqdn(zone)
+	case strings.HasSuffix(name, "."):
+		return name
+	}
+	return name + "." + Fqdn(zone)
+}
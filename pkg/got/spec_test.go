@@ -0,0 +1,158 @@
+package got
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var specContents = `zone: example.com
+ttl: 300
+records:
+  - name: "@"
+    type: mx
+    values:
+      - 10 mx1.example.com.
+  - name: www
+    type: A
+    ttl: 60
+    values:
+      - 10.0.0.1
+      - 10.0.0.2
+  - name: other.example.org.
+    type: CNAME
+    values:
+      - www.example.com.
+`
+
+func TestLoadSpec(t *testing.T) {
+	spec, err := LoadSpec(strings.NewReader(specContents))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := []string{
+		"example.com. MX 300",
+		"www.example.com. A 60",
+		"other.example.org. CNAME 300",
+	}
+	records := spec.RecordSets()
+	if len(records) != len(expected) {
+		t.Fatalf(
+			"Expected %d record sets, received %d",
+			len(expected),
+			len(records),
+		)
+	}
+	for i, rrs := range records {
+		out := strings.Join(
+			[]string{*rrs.Name, *rrs.Type, formatTTL(rrs)},
+			" ",
+		)
+		if out != expected[i] {
+			t.Errorf("Expected %s, received %s", expected[i], out)
+		}
+	}
+}
+
+var invalidSpecTests = []struct {
+	contents string
+	err      string
+}{
+	{
+		"records: []",
+		"no zone specified",
+	},
+	{
+		"zone: example.com\nrecords:\n  - name: www\n    type: A\n    values: [10.0.0.1]",
+		"record www A has no TTL",
+	},
+	{
+		"zone: example.com\nttl: 60\nrecords:\n  - name: www\n    type: A",
+		"record www A has no values",
+	},
+	{
+		"zone: example.com\nttl: 60\nrecords:\n" +
+			"  - {name: www, type: A, values: [10.0.0.1]}\n" +
+			"  - {name: www.example.com., type: A, values: [10.0.0.2]}",
+		"record www.example.com. A is duplicated",
+	},
+}
+
+func TestLoadSpecInvalid(t *testing.T) {
+	for _, tt := range invalidSpecTests {
+		t.Run(tt.err, func(t *testing.T) {
+			_, err := LoadSpec(strings.NewReader(tt.contents))
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %s, received %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSpecChanges(t *testing.T) {
+	spec := &Spec{
+		Zone: "example.com",
+		TTL:  300,
+		Records: []SpecRecord{
+			{Name: "same", Type: "A", Values: []string{"10.0.0.1", "10.0.0.2"}},
+			{Name: "new", Type: "A", Values: []string{"10.0.0.5"}},
+		},
+	}
+	changes := spec.Changes(diffCurrent, false)
+	if len(changes) != 1 || *changes[0].Action != "CREATE" {
+		t.Errorf("Expected a single CREATE, received %v", changes)
+	}
+	changes = spec.Changes(diffCurrent, true)
+	if len(changes) != 5 {
+		t.Errorf("Expected 4 DELETE and a CREATE, received %v", changes)
+	}
+	// Weighted record sets are never pruned.
+	changes = spec.Changes(append(routingList, diffCurrent...), true)
+	if len(changes) != 5 {
+		t.Errorf("Expected 4 DELETE and a CREATE, received %v", changes)
+	}
+}
+
+func TestSpecConflicts(t *testing.T) {
+	spec := &Spec{
+		Zone: "example.com",
+		TTL:  300,
+		Records: []SpecRecord{
+			{Name: "www", Type: "A", Values: []string{"10.0.0.1"}},
+			{Name: "lb", Type: "A", Values: []string{"10.0.0.2"}},
+			{Name: "*", Type: "A", Values: []string{"10.0.0.3"}},
+			{Name: "same", Type: "A", Values: []string{"10.0.0.4"}},
+		},
+	}
+	current := append([]*route53.ResourceRecordSet{
+		{
+			Name: aws.String("lb.example.com."),
+			Type: aws.String("A"),
+			AliasTarget: &route53.AliasTarget{
+				DNSName:      aws.String("lb.elb.amazonaws.com."),
+				HostedZoneId: aws.String("Z35SXDOTRQ7X7K"),
+			},
+		},
+	}, append(routingList, diffCurrent...)...)
+	expected := []string{
+		"record www.example.com. A conflicts with an alias or routing policy",
+		"record lb.example.com. A conflicts with an alias or routing policy",
+		"record *.example.com. A conflicts with the CNAME record of the name",
+	}
+	errs := spec.Conflicts(current, false)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d conflicts, received %v", len(expected), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected %s, received %s", expected[i], err)
+		}
+	}
+	// The wildcard CNAME is deleted before creating the A record when
+	// pruning.
+	if errs = spec.Conflicts(current, true); len(errs) != 2 {
+		t.Errorf("Expected 2 conflicts, received %v", errs)
+	}
+}
@@ -0,0 +1,246 @@
+package got
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/miekg/dns"
+)
+
+// Resolver sends DNS queries to servers.
+type Resolver interface {
+	// Exchange sends the query to server and returns its answer.
+	Exchange(m *dns.Msg, server string) (*dns.Msg, error)
+}
+
+// Ensure the resolvers implement the interface.
+var _ Resolver = &DNSResolver{}
+
+// DNSResolver is the Resolver sending queries over the network.
+type DNSResolver struct {
+	Client *dns.Client
+	// Port is used for servers specified without one.
+	Port string
+}
+
+// NewDNSResolver returns the Resolver sending queries to port of the
+// servers, 53 if empty.
+func NewDNSResolver(port string) *DNSResolver {
+	if port == "" {
+		port = "53"
+	}
+	return &DNSResolver{
+		Client: &dns.Client{},
+		Port:   port,
+	}
+}
+
+// Exchange sends the query to server, which may be a name or an address,
+// with or without port. Truncated answers are queried again over TCP.
+func (r *DNSResolver) Exchange(m *dns.Msg, server string) (*dns.Msg, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.TrimSuffix(server, "."), r.Port)
+	}
+	in, _, err := r.Client.Exchange(m, server)
+	if err == nil && in.Truncated && !strings.HasPrefix(r.Client.Net, "tcp") {
+		tcp := &dns.Client{Net: "tcp", Timeout: r.Client.Timeout}
+		in, _, err = tcp.Exchange(m, server)
+	}
+	return in, err
+}
+
+// SystemResolvers returns the resolvers in /etc/resolv.conf, with port.
+func SystemResolvers() (resolvers []string, err error) {
+	config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil {
+		return
+	}
+	for _, server := range config.Servers {
+		resolvers = append(resolvers, net.JoinHostPort(server, config.Port))
+	}
+	return
+}
+
+// Verification is the result of checking the answer of a DNS server for a
+// record set.
+type Verification struct {
+	Server string
+	Name   string
+	Type   string
+	// Answer is the record set answered, or nil if there was none.
+	Answer *route53.ResourceRecordSet
+	Match  bool
+	Err    error
+}
+
+// String describes the verification in a line.
+func (v *Verification) String() string {
+	status := "ok"
+	switch {
+	case v.Err != nil:
+		status = "error: " + v.Err.Error()
+	case !v.Match && v.Answer == nil:
+		status = "mismatch: no answer"
+	case !v.Match:
+		status = fmt.Sprintf(
+			"mismatch: %s %s",
+			formatTTL(v.Answer),
+			formatValues(v.Answer),
+		)
+	}
+	return fmt.Sprintf("%s\t%s\t%s\t%s", v.Server, v.Name, v.Type, status)
+}
+
+// DelegatedNameServers returns the names of the servers the parent zone
+// delegates zone to, as seen by the rest of the Internet, rather than those
+// in the NS record set of the zone itself. The name servers of the parent
+// are looked up through resolver, and then asked for the delegation.
+func DelegatedNameServers(
+	r Resolver,
+	resolver string,
+	zone string,
+) (servers []string, err error) {
+	zone = strings.ToLower(Fqdn(zone))
+	parents, err := parentNameServers(r, resolver, zone)
+	if err != nil {
+		return
+	}
+	for _, parent := range parents {
+		m := new(dns.Msg)
+		m.SetQuestion(zone, dns.TypeNS)
+		m.RecursionDesired = false
+		var in *dns.Msg
+		if in, err = r.Exchange(m, parent); err != nil {
+			continue
+		}
+		// Referrals hold the delegation in the authority section.
+		if servers = nameServers(append(in.Answer, in.Ns...), zone); len(servers) > 0 {
+			return servers, nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no delegation found for %s", zone)
+	}
+	return
+}
+
+// parentNameServers returns the names of the servers of the closest zone
+// above zone, asking resolver.
+func parentNameServers(
+	r Resolver,
+	resolver string,
+	zone string,
+) (servers []string, err error) {
+	labels := dns.SplitDomainName(zone)
+	for i := 1; i <= len(labels); i++ {
+		parent := dns.Fqdn(strings.Join(labels[i:], "."))
+		m := new(dns.Msg)
+		m.SetQuestion(parent, dns.TypeNS)
+		var in *dns.Msg
+		if in, err = r.Exchange(m, resolver); err != nil {
+			return
+		}
+		if servers = nameServers(in.Answer, parent); len(servers) > 0 {
+			return
+		}
+	}
+	err = fmt.Errorf("no parent zone found for %s", zone)
+	return
+}
+
+// nameServers returns the names of the servers in the NS records of name
+// among rrs.
+func nameServers(rrs []dns.RR, name string) (servers []string) {
+	for _, rr := range rrs {
+		if ns, ok := rr.(*dns.NS); ok && strings.EqualFold(ns.Hdr.Name, name) {
+			servers = append(servers, ns.Ns)
+		}
+	}
+	return
+}
+
+// VerifyRecordSets queries every server for the record sets in expected,
+// reporting whether their answers match. Authoritative servers must answer
+// with the same TTL, while resolvers may answer with a cached, lower one.
+// Record sets with no values are expected not to exist. Aliases and record
+// sets with routing policies other than simple are skipped, as their
+// answers depend on the target or the client.
+func VerifyRecordSets(
+	r Resolver,
+	servers []string,
+	expected []*route53.ResourceRecordSet,
+	authoritative bool,
+) (verifications []*Verification) {
+	for _, server := range servers {
+		for _, rrs := range expected {
+			if !RoutingOf(rrs).IsSimple() {
+				continue
+			}
+			verifications = append(
+				verifications,
+				verifyRecordSet(r, server, rrs, authoritative),
+			)
+		}
+	}
+	return
+}
+
+// ExpectedRecordSets returns the record sets resulting from the changes.
+// Those deleted are returned with no values.
+func ExpectedRecordSets(
+	changes []*route53.Change,
+) (expected []*route53.ResourceRecordSet) {
+	for _, change := range changes {
+		rrs := change.ResourceRecordSet
+		if *change.Action == route53.ChangeActionDelete {
+			rrs = &route53.ResourceRecordSet{Name: rrs.Name, Type: rrs.Type}
+		}
+		expected = append(expected, rrs)
+	}
+	return
+}
+
+// verifyRecordSet queries server for the record set expected.
+func verifyRecordSet(
+	r Resolver,
+	server string,
+	expected *route53.ResourceRecordSet,
+	authoritative bool,
+) (v *Verification) {
+	v = &Verification{
+		Server: server,
+		Name:   *expected.Name,
+		Type:   *expected.Type,
+	}
+	m := new(dns.Msg)
+	m.SetQuestion(
+		zoneFileName(Fqdn(*expected.Name)),
+		dns.StringToType[*expected.Type],
+	)
+	m.RecursionDesired = !authoritative
+	in, err := r.Exchange(m, server)
+	if err != nil {
+		v.Err = err
+		return
+	}
+	if in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError {
+		v.Err = fmt.Errorf("query failed: %s", dns.RcodeToString[in.Rcode])
+		return
+	}
+	v.Answer = findRecordSet(recordSetsFromRRs(in.Answer), v.Name, v.Type)
+	if len(expected.ResourceRecords) == 0 {
+		v.Match = v.Answer == nil
+		return
+	}
+	if v.Answer == nil {
+		return
+	}
+	answer := *v.Answer
+	if !authoritative {
+		answer.TTL = expected.TTL
+	}
+	v.Match = recordSetEqual(&answer, expected)
+	return
+}
@@ -0,0 +1,241 @@
+package got
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/miekg/dns"
+)
+
+// newNameServer starts a DNS server on UDP answering queries from the
+// records in contents, and returns its port.
+func newNameServer(t *testing.T, zone string, contents string) string {
+	rrs := []dns.RR{}
+	zp := dns.NewZoneParser(strings.NewReader(contents), dns.Fqdn(zone), "")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		rrs = append(rrs, rr)
+	}
+	if err := zp.Err(); err != nil {
+		t.Fatalf("Invalid zone: %s", err)
+	}
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	started := make(chan bool)
+	server := &dns.Server{
+		PacketConn:        conn,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			m.Authoritative = true
+			q := r.Question[0]
+			for _, rr := range rrs {
+				if strings.EqualFold(rr.Header().Name, q.Name) &&
+					rr.Header().Rrtype == q.Qtype {
+					m.Answer = append(m.Answer, rr)
+				}
+			}
+			if err := w.WriteMsg(m); err != nil {
+				panic(err)
+			}
+		}),
+	}
+	go func() {
+		if err := server.ActivateAndServe(); err != nil {
+			t.Logf("Server stopped: %s", err)
+		}
+	}()
+	<-started
+	t.Cleanup(func() {
+		if err := server.Shutdown(); err != nil {
+			t.Logf("Unable to stop server: %s", err)
+		}
+	})
+	_, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+	return port
+}
+
+var verifyCases = []struct {
+	name          string
+	expected      *route53.ResourceRecordSet
+	authoritative bool
+	match         bool
+}{
+	{
+		name:          "Matching values and TTL",
+		expected:      newRecordSet("www.example.com.", "A", 300, "10.0.0.2", "10.0.0.1"),
+		authoritative: true,
+		match:         true,
+	},
+	{
+		name:          "Different TTL from authoritative server",
+		expected:      newRecordSet("www.example.com.", "A", 60, "10.0.0.1", "10.0.0.2"),
+		authoritative: true,
+		match:         false,
+	},
+	{
+		name:          "Different TTL from resolver",
+		expected:      newRecordSet("www.example.com.", "A", 60, "10.0.0.1", "10.0.0.2"),
+		authoritative: false,
+		match:         true,
+	},
+	{
+		name:          "Stale values",
+		expected:      newRecordSet("www.example.com.", "A", 300, "10.0.0.3"),
+		authoritative: true,
+		match:         false,
+	},
+	{
+		name:          "Escaped TXT",
+		expected:      newRecordSet("txt.example.com.", "TXT", 300, "\"caf\\351\""),
+		authoritative: true,
+		match:         true,
+	},
+	{
+		name: "Deleted record set",
+		expected: &route53.ResourceRecordSet{
+			Name: aws.String("gone.example.com."),
+			Type: aws.String("A"),
+		},
+		authoritative: true,
+		match:         true,
+	},
+	{
+		name: "Not yet deleted record set",
+		expected: &route53.ResourceRecordSet{
+			Name: aws.String("www.example.com."),
+			Type: aws.String("A"),
+		},
+		authoritative: true,
+		match:         false,
+	},
+}
+
+func TestVerifyRecordSets(t *testing.T) {
+	port := newNameServer(t, "example.com", authoritativeZone+"txt\tIN\tTXT\t\"caf\\233\"\n")
+	r := NewDNSResolver(port)
+	for _, tc := range verifyCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := VerifyRecordSets(
+				r,
+				[]string{"127.0.0.1"},
+				[]*route53.ResourceRecordSet{tc.expected},
+				tc.authoritative,
+			)
+			if len(out) != 1 {
+				t.Fatalf("Expected 1 verification, received %d", len(out))
+			}
+			if out[0].Err != nil {
+				t.Fatalf("Unexpected error %s", out[0].Err)
+			}
+			if out[0].Match != tc.match {
+				t.Errorf("Expected match %v, received %s", tc.match, out[0])
+			}
+		})
+	}
+}
+
+func TestVerifyExpectedRecordSets(t *testing.T) {
+	port := newNameServer(t, "example.com", authoritativeZone)
+	out := VerifyRecordSets(
+		NewDNSResolver(port),
+		[]string{"127.0.0.1", "127.0.0.1:" + port},
+		ExpectedRecordSets([]*route53.Change{
+			{
+				Action:            aws.String("UPSERT"),
+				ResourceRecordSet: routingList[0],
+			},
+			{
+				Action:            aws.String("DELETE"),
+				ResourceRecordSet: newRecordSet("old.example.com.", "CNAME", 300, "www.example.com."),
+			},
+		}),
+		true,
+	)
+	if len(out) != 2 {
+		t.Fatalf("Expected 2 verifications, received %d", len(out))
+	}
+	for _, v := range out {
+		if v.Err != nil || v.Match {
+			t.Errorf("Expected the CNAME to still exist, received %s", v)
+		}
+	}
+}
+
+func TestDelegatedNameServers(t *testing.T) {
+	// The same server answers as resolver and as name server of com.
+	port := newNameServer(t, "com", `
+@	IN	NS	localhost.
+example	IN	NS	ns-1.awsdns-1.com.
+example	IN	NS	ns-2.awsdns-2.net.
+`)
+	r := NewDNSResolver(port)
+	out, err := DelegatedNameServers(r, "127.0.0.1", "Example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if strings.Join(out, " ") != "ns-1.awsdns-1.com. ns-2.awsdns-2.net." {
+		t.Errorf("Unexpected name servers %v", out)
+	}
+	_, err = DelegatedNameServers(r, "127.0.0.1", "missing.com")
+	if err == nil || err.Error() != "no delegation found for missing.com." {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestDNSResolverTruncated(t *testing.T) {
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		if w.RemoteAddr().Network() == "udp" {
+			m.Truncated = true
+		} else {
+			rr, _ := dns.NewRR("www.example.com. 300 IN A 10.0.0.1")
+			m.Answer = append(m.Answer, rr)
+		}
+		if err := w.WriteMsg(m); err != nil {
+			panic(err)
+		}
+	})
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	listener, err := net.Listen("tcp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Unable to listen: %s", err)
+	}
+	for _, server := range []*dns.Server{
+		{PacketConn: conn, Handler: handler},
+		{Listener: listener, Handler: handler},
+	} {
+		server := server
+		started := make(chan bool)
+		server.NotifyStartedFunc = func() { close(started) }
+		go func() {
+			if err := server.ActivateAndServe(); err != nil {
+				t.Logf("Server stopped: %s", err)
+			}
+		}()
+		<-started
+		t.Cleanup(func() {
+			if err := server.Shutdown(); err != nil {
+				t.Logf("Unable to stop server: %s", err)
+			}
+		})
+	}
+	m := new(dns.Msg)
+	m.SetQuestion("www.example.com.", dns.TypeA)
+	in, err := NewDNSResolver("").Exchange(m, conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if in.Truncated || len(in.Answer) != 1 {
+		t.Errorf("Expected the answer over TCP, received %v", in)
+	}
+}
@@ -0,0 +1,149 @@
+package got
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// NewVPC returns the VPC with ID id in region.
+func NewVPC(id, region string) *route53.VPC {
+	return &route53.VPC{
+		VPCId:     aws.String(id),
+		VPCRegion: aws.String(region),
+	}
+}
+
+// ZoneVPCs returns the VPCs associated with the private hosted zone, and
+// those owned by other accounts authorized to be associated with it.
+func ZoneVPCs(
+	zoneID string,
+	svc route53iface.Route53API,
+) (associated, authorized []*route53.VPC, err error) {
+	zone, err := svc.GetHostedZone(&route53.GetHostedZoneInput{
+		Id: aws.String(zoneID),
+	})
+	if err != nil {
+		return
+	}
+	if !isPrivateZone(zone.HostedZone) {
+		err = fmt.Errorf("zone %s is public", zoneID)
+		return
+	}
+	associated = zone.VPCs
+	params := &route53.ListVPCAssociationAuthorizationsInput{
+		HostedZoneId: aws.String(zoneID),
+	}
+	for {
+		var resp *route53.ListVPCAssociationAuthorizationsOutput
+		resp, err = svc.ListVPCAssociationAuthorizations(params)
+		if err != nil {
+			return
+		}
+		authorized = append(authorized, resp.VPCs...)
+		if resp.NextToken == nil {
+			return
+		}
+		params.NextToken = resp.NextToken
+	}
+}
+
+// AssociateVPC associates the VPC with the private hosted zone. If the
+// zone belongs to another account, it must have authorized the VPC with
+// AuthorizeVPC first.
+func AssociateVPC(
+	zoneID string,
+	vpc *route53.VPC,
+	svc route53iface.Route53API,
+) (changeInfo *route53.ChangeInfo, err error) {
+	params := &route53.AssociateVPCWithHostedZoneInput{
+		HostedZoneId: aws.String(zoneID),
+		VPC:          vpc,
+	}
+	if err = params.Validate(); err != nil {
+		return
+	}
+	out, err := svc.AssociateVPCWithHostedZone(params)
+	if err != nil {
+		return
+	}
+	changeInfo = out.ChangeInfo
+	return
+}
+
+// DisassociateVPC disassociates the VPC from the private hosted zone. The
+// last VPC associated with a zone can't be disassociated.
+func DisassociateVPC(
+	zoneID string,
+	vpc *route53.VPC,
+	svc route53iface.Route53API,
+) (changeInfo *route53.ChangeInfo, err error) {
+	params := &route53.DisassociateVPCFromHostedZoneInput{
+		HostedZoneId: aws.String(zoneID),
+		VPC:          vpc,
+	}
+	if err = params.Validate(); err != nil {
+		return
+	}
+	out, err := svc.DisassociateVPCFromHostedZone(params)
+	if err != nil {
+		return
+	}
+	changeInfo = out.ChangeInfo
+	return
+}
+
+// AuthorizeVPC authorizes the VPC, owned by another account, to be
+// associated with the private hosted zone from that account.
+func AuthorizeVPC(
+	zoneID string,
+	vpc *route53.VPC,
+	svc route53iface.Route53API,
+) error {
+	params := &route53.CreateVPCAssociationAuthorizationInput{
+		HostedZoneId: aws.String(zoneID),
+		VPC:          vpc,
+	}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	_, err := svc.CreateVPCAssociationAuthorization(params)
+	return err
+}
+
+// RevokeVPC revokes the authorization of the VPC, owned by another
+// account, to be associated with the private hosted zone. VPCs already
+// associated remain so.
+func RevokeVPC(
+	zoneID string,
+	vpc *route53.VPC,
+	svc route53iface.Route53API,
+) error {
+	params := &route53.DeleteVPCAssociationAuthorizationInput{
+		HostedZoneId: aws.String(zoneID),
+		VPC:          vpc,
+	}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	_, err := svc.DeleteVPCAssociationAuthorization(params)
+	return err
+}
+
+// WriteZoneVPCs writes a table of the VPCs associated with a zone and
+// those authorized to be associated with it.
+func WriteZoneVPCs(w io.Writer, associated, authorized []*route53.VPC) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "VPC ID\tREGION\tSTATUS")
+	for _, vpc := range associated {
+		fmt.Fprintf(tw, "%s\t%s\tassociated\n", *vpc.VPCId, *vpc.VPCRegion)
+	}
+	for _, vpc := range authorized {
+		fmt.Fprintf(tw, "%s\t%s\tauthorized\n", *vpc.VPCId, *vpc.VPCRegion)
+	}
+	return tw.Flush()
+}
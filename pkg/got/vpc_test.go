@@ -0,0 +1,67 @@
+package got
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
+)
+
+func TestZoneVPCs(t *testing.T) {
+	svc := mocks.NewRoute53Client()
+	svc.PageSize = 1
+	public := svc.AddHostedZone("example.com")
+	private := svc.AddHostedZone("example.com", "vpc-1")
+
+	if _, _, err := ZoneVPCs(public, svc); err == nil {
+		t.Error("Expected error listing VPCs of public zone")
+	}
+	if _, err := AssociateVPC(public, NewVPC("vpc-2", "eu-west-1"), svc); err == nil {
+		t.Error("Expected error associating VPC with public zone")
+	}
+	changeInfo, err := AssociateVPC(private, NewVPC("vpc-2", "eu-west-1"), svc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if changeInfo.Id == nil {
+		t.Error("Expected change ID")
+	}
+	for _, id := range []string{"vpc-3", "vpc-4"} {
+		if err = AuthorizeVPC(private, NewVPC(id, "us-west-2"), svc); err != nil {
+			t.Fatalf("Unexpected error %s", err)
+		}
+	}
+	if err = RevokeVPC(private, NewVPC("vpc-4", "us-west-2"), svc); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if err = RevokeVPC(private, NewVPC("vpc-4", "us-west-2"), svc); err == nil {
+		t.Error("Expected error revoking VPC not authorized")
+	}
+	if err = AuthorizeVPC(private, NewVPC("vpc-5", ""), svc); err == nil {
+		t.Error("Expected error authorizing VPC without region")
+	}
+
+	associated, authorized, err := ZoneVPCs(private, svc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	buf := &bytes.Buffer{}
+	if err = WriteZoneVPCs(buf, associated, authorized); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	out := `VPC ID REGION    STATUS
+vpc-1  us-east-1 associated
+vpc-2  eu-west-1 associated
+vpc-3  us-west-2 authorized
+`
+	if buf.String() != out {
+		t.Errorf("Unexpected VPCs:\n%s", buf.String())
+	}
+
+	if _, err = DisassociateVPC(private, NewVPC("vpc-1", "us-east-1"), svc); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if _, err = DisassociateVPC(private, NewVPC("vpc-2", "eu-west-1"), svc); err == nil {
+		t.Error("Expected error disassociating last VPC")
+	}
+}
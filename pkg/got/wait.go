@@ -0,0 +1,150 @@
+package got
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// ChangeChecker reports the status of batches of changes.
+type ChangeChecker interface {
+	// Status returns whether the batch of changes identified by id is
+	// applied, along with the status reported by the service.
+	Status(ctx context.Context, id string) (done bool, status string, err error)
+}
+
+// WaitProgress describes the status of a batch of changes being waited for.
+type WaitProgress struct {
+	ID      string
+	Status  string
+	Elapsed time.Duration
+	// Err is the error checking the status, if any. Errors that may go
+	// away, such as throttling, are retried until the batch is applied or
+	// the wait is cancelled, while the rest end the wait.
+	Err error
+}
+
+// Waiter polls the status of batches of changes until they are applied,
+// sleeping between polls for a time increasing linearly from Interval up
+// to MaxInterval.
+type Waiter struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+	// Progress, if not nil, is called after every poll.
+	Progress func(WaitProgress)
+}
+
+// NewWaiter returns a Waiter polling every second at first, and every 30
+// seconds at most.
+func NewWaiter() *Waiter {
+	return &Waiter{
+		Interval:    time.Second,
+		MaxInterval: 30 * time.Second,
+	}
+}
+
+// Wait blocks until every batch of changes identified by ids is applied,
+// ctx is done, or checking the status of a batch fails with an error that
+// can't be retried, whatever happens first.
+func (w *Waiter) Wait(ctx context.Context, c ChangeChecker, ids ...string) error {
+	for _, id := range ids {
+		if err := w.wait(ctx, c, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wait blocks until the batch of changes identified by id is applied.
+func (w *Waiter) wait(ctx context.Context, c ChangeChecker, id string) error {
+	start := time.Now()
+	sleep := w.Interval
+	for {
+		done, status, err := c.Status(ctx, id)
+		if w.Progress != nil {
+			w.Progress(WaitProgress{
+				ID:      id,
+				Status:  status,
+				Elapsed: time.Since(start),
+				Err:     err,
+			})
+		}
+		if err == nil && done {
+			return nil
+		}
+		if err != nil && ctx.Err() == nil && !retryable(err) {
+			return fmt.Errorf("unable to check change %s: %w", id, err)
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf(
+				"change %s not applied after %s: %w",
+				id,
+				time.Since(start).Round(time.Second),
+				ctx.Err(),
+			)
+		case <-timer.C:
+		}
+		if sleep < w.MaxInterval {
+			sleep += w.Interval
+		}
+	}
+}
+
+// retryable returns whether the error checking the status of a change may
+// go away by retrying, as AWS throttling and server errors do. Errors not
+// coming from AWS, such as network ones, are retried too.
+func retryable(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return true
+	}
+	if request.IsErrorThrottle(err) || request.IsErrorRetryable(err) {
+		return true
+	}
+	var rerr awserr.RequestFailure
+	return errors.As(err, &rerr) && rerr.StatusCode() >= 500
+}
+
+// route53ChangeChecker is the ChangeChecker for Route53 changes.
+type route53ChangeChecker struct {
+	svc route53iface.Route53API
+}
+
+// Status returns whether the change identified by id is INSYNC.
+func (c *route53ChangeChecker) Status(
+	ctx context.Context,
+	id string,
+) (done bool, status string, err error) {
+	out, err := c.svc.GetChangeWithContext(ctx, &route53.GetChangeInput{
+		Id: &id,
+	})
+	if err != nil {
+		return
+	}
+	status = *out.ChangeInfo.Status
+	done = status == route53.ChangeStatusInsync
+	return
+}
+
+// WaitForChangeToComplete waits until the ChangeInfo described by the
+// argument is completed, or ctx is done.
+func WaitForChangeToComplete(
+	ctx context.Context,
+	changeInfo *route53.ChangeInfo,
+	svc route53iface.Route53API,
+) error {
+	return NewWaiter().Wait(
+		ctx,
+		&route53ChangeChecker{svc: svc},
+		*changeInfo.Id,
+	)
+}
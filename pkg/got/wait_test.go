@@ -0,0 +1,153 @@
+package got
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// scriptedChecker is a ChangeChecker answering with the statuses, or
+// errors, in script, in order, and repeating the last one.
+type scriptedChecker struct {
+	script []interface{}
+	polls  int
+}
+
+func (c *scriptedChecker) Status(
+	ctx context.Context,
+	id string,
+) (done bool, status string, err error) {
+	i := c.polls
+	if i >= len(c.script) {
+		i = len(c.script) - 1
+	}
+	c.polls++
+	switch step := c.script[i].(type) {
+	case error:
+		err = step
+	case string:
+		status = step
+		done = step == route53.ChangeStatusInsync
+	}
+	return
+}
+
+var waiterCases = []struct {
+	name     string
+	script   []interface{}
+	timeout  time.Duration
+	polls    int
+	progress string
+	err      bool
+}{
+	{
+		name:     "Already applied",
+		script:   []interface{}{"INSYNC"},
+		timeout:  time.Second,
+		polls:    1,
+		progress: "[C1 INSYNC]",
+	},
+	{
+		name:     "Pending and retried errors",
+		script:   []interface{}{"PENDING", errors.New("throttled"), "INSYNC"},
+		timeout:  time.Second,
+		polls:    3,
+		progress: "[C1 PENDING C1 throttled C1 INSYNC]",
+	},
+	{
+		name:     "Throttled by AWS",
+		script:   []interface{}{awserr.New("Throttling", "Rate exceeded", nil), "INSYNC"},
+		timeout:  time.Second,
+		polls:    2,
+		progress: "[C1 Throttling: Rate exceeded C1 INSYNC]",
+	},
+	{
+		name: "AWS server error",
+		script: []interface{}{
+			awserr.NewRequestFailure(awserr.New("InternalError", "Oops", nil), 500, "R1"),
+			"INSYNC",
+		},
+		timeout:  time.Second,
+		polls:    2,
+		progress: "[C1 InternalError: Oops\n\tstatus code: 500, request id: R1 C1 INSYNC]",
+	},
+	{
+		name:    "Deadline exceeded",
+		script:  []interface{}{"PENDING"},
+		timeout: 25 * time.Millisecond,
+		err:     true,
+	},
+}
+
+func TestWaiter(t *testing.T) {
+	for _, tc := range waiterCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &scriptedChecker{script: tc.script}
+			progress := []string{}
+			w := &Waiter{
+				Interval:    time.Millisecond,
+				MaxInterval: 5 * time.Millisecond,
+				Progress: func(p WaitProgress) {
+					if p.Err != nil {
+						progress = append(progress, p.ID, p.Err.Error())
+						return
+					}
+					progress = append(progress, p.ID, p.Status)
+				},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			err := w.Wait(ctx, c, "C1")
+			if tc.err {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("Expected deadline to be exceeded, received %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			if c.polls != tc.polls {
+				t.Errorf("Expected %d polls, received %d", tc.polls, c.polls)
+			}
+			if fmt.Sprint(progress) != tc.progress {
+				t.Errorf("Expected progress %s, received %v", tc.progress, progress)
+			}
+		})
+	}
+}
+
+func TestWaiterPermanentError(t *testing.T) {
+	permanent := awserr.NewRequestFailure(
+		awserr.New(route53.ErrCodeNoSuchChange, "No such change", nil),
+		404,
+		"R1",
+	)
+	c := &scriptedChecker{script: []interface{}{permanent}}
+	w := &Waiter{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+	// Without deadline, the wait would never end if the error was retried.
+	err := w.Wait(context.Background(), c, "C1")
+	if !errors.Is(err, permanent) {
+		t.Errorf("Expected the permanent error, received %v", err)
+	}
+	if c.polls != 1 {
+		t.Errorf("Expected 1 poll, received %d", c.polls)
+	}
+}
+
+func TestWaitForChangeToComplete(t *testing.T) {
+	err := WaitForChangeToComplete(
+		context.Background(),
+		&route53.ChangeInfo{Id: aws.String("/change/C1")},
+		&mockRoute53Client{},
+	)
+	if err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+}
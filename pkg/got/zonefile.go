@@ -0,0 +1,398 @@
+package got
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/miekg/dns"
+)
+
+// maxCharacterString is the maximum length of a single <character-string>
+// as defined in RFC 1035, section 3.3.
+const maxCharacterString = 255
+
+// defaultZoneTTL is used as $TTL when there are no records to derive it from.
+const defaultZoneTTL int64 = 300
+
+// WriteZoneFile writes the records as an RFC 1035 master file for the zone
+// named origin. Every value of multi-value records is written on its own
+// line, and alias records, which have no representation in a master file,
+// are written as comments.
+func WriteZoneFile(
+	w io.Writer,
+	origin string,
+	records []*route53.ResourceRecordSet,
+) (err error) {
+	origin = Fqdn(origin)
+	if _, err = fmt.Fprintf(
+		w,
+		"$ORIGIN %s\n$TTL %d\n",
+		origin,
+		zoneTTL(records),
+	); err != nil {
+		return
+	}
+	for _, rrs := range records {
+		owner := relativeName(zoneFileName(*rrs.Name), origin)
+		if rrs.AliasTarget != nil {
+			_, err = fmt.Fprintf(
+				w,
+				"; %s\tALIAS\t%s\t%s\t%s\n",
+				owner,
+				*rrs.Type,
+				zoneFileName(*rrs.AliasTarget.DNSName),
+				*rrs.AliasTarget.HostedZoneId,
+			)
+			if err != nil {
+				return
+			}
+			continue
+		}
+		for _, rr := range rrs.ResourceRecords {
+			value := *rr.Value
+			switch *rrs.Type {
+			case "TXT", "SPF":
+				value = txtValue(value)
+			}
+			_, err = fmt.Fprintf(
+				w,
+				"%s\t%d\tIN\t%s\t%s\n",
+				owner,
+				*rrs.TTL,
+				*rrs.Type,
+				value,
+			)
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// ParseZoneFile reads an RFC 1035 master file for the zone named origin and
+// returns its records grouped in record sets, the same way Route53 lists
+// them. Record sets whose records have different TTLs are rejected, as
+// Route53 has a single TTL per record set. TXT strings longer than 255
+// bytes are split in several, as name servers do.
+func ParseZoneFile(
+	r io.Reader,
+	origin string,
+) (
+	records []*route53.ResourceRecordSet,
+	err error,
+) {
+	list := []dns.RR{}
+	zp := dns.NewZoneParser(r, Fqdn(origin), "")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		list = append(list, rr)
+	}
+	if err = zp.Err(); err != nil {
+		return
+	}
+	if err = checkTTLs(list); err != nil {
+		return
+	}
+	records = recordSetsFromRRs(list)
+	return
+}
+
+// checkTTLs returns an error if records of the same record set have
+// different TTLs.
+func checkTTLs(list []dns.RR) error {
+	ttls := map[string]uint32{}
+	for _, rr := range list {
+		header := rr.Header()
+		name := route53Name(header.Name)
+		typ := dns.TypeToString[header.Rrtype]
+		key := name + " " + typ
+		ttl, found := ttls[key]
+		if !found {
+			ttls[key] = header.Ttl
+			continue
+		}
+		if ttl != header.Ttl {
+			return fmt.Errorf(
+				"record set %s %s has records with TTLs %d and %d",
+				name,
+				typ,
+				ttl,
+				header.Ttl,
+			)
+		}
+	}
+	return nil
+}
+
+// recordSetsFromRRs groups DNS resource records in record sets, converting
+// names and values to the syntax used by Route53.
+func recordSetsFromRRs(list []dns.RR) (records []*route53.ResourceRecordSet) {
+	index := map[string]*route53.ResourceRecordSet{}
+	for _, rr := range list {
+		header := rr.Header()
+		name := route53Name(header.Name)
+		typ := dns.TypeToString[header.Rrtype]
+		value := strings.TrimPrefix(rr.String(), header.String())
+		switch typ {
+		case "TXT", "SPF":
+			value = route53TXT(value)
+		}
+		key := name + " " + typ
+		set, found := index[key]
+		if !found {
+			set = &route53.ResourceRecordSet{
+				Name: aws.String(name),
+				Type: aws.String(typ),
+				TTL:  aws.Int64(int64(header.Ttl)),
+			}
+			index[key] = set
+			records = append(records, set)
+		}
+		set.ResourceRecords = append(
+			set.ResourceRecords,
+			&route53.ResourceRecord{Value: aws.String(value)},
+		)
+	}
+	return
+}
+
+// rrsFromRecordSet converts a record set into DNS resource records, one
+// per value.
+func rrsFromRecordSet(
+	rrs *route53.ResourceRecordSet,
+) (result []dns.RR, err error) {
+	if rrs.AliasTarget != nil {
+		err = fmt.Errorf("alias record %s can't be converted", *rrs.Name)
+		return
+	}
+	for _, record := range rrs.ResourceRecords {
+		value := *record.Value
+		switch *rrs.Type {
+		case "TXT", "SPF":
+			value = txtValue(value)
+		}
+		var rr dns.RR
+		rr, err = dns.NewRR(fmt.Sprintf(
+			"%s %d IN %s %s",
+			zoneFileName(*rrs.Name),
+			aws.Int64Value(rrs.TTL),
+			*rrs.Type,
+			value,
+		))
+		if err != nil {
+			return
+		}
+		result = append(result, rr)
+	}
+	return
+}
+
+// Fqdn returns name with a trailing dot, as used by Route53.
+func Fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// zoneTTL returns the default TTL for the zone, which is the one of the SOA
+// record when present.
+func zoneTTL(records []*route53.ResourceRecordSet) int64 {
+	for _, rrs := range records {
+		if *rrs.Type == "SOA" && rrs.TTL != nil {
+			return *rrs.TTL
+		}
+	}
+	return defaultZoneTTL
+}
+
+// relativeName returns name relative to origin, using @ for the apex, or
+// the name unchanged when it's outside origin.
+func relativeName(name, origin string) string {
+	if strings.EqualFold(name, origin) {
+		return "@"
+	}
+	suffix := "." + origin
+	if len(name) > len(suffix) &&
+		strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+		return name[:len(name)-len(suffix)]
+	}
+	return name
+}
+
+// decodeEscapes returns the raw bytes of a presentation string, where \DDD
+// escapes are numbers in base, and \c is the character c. Route53 uses
+// octal escapes, while master files use decimal ones.
+func decodeEscapes(s string, base int) []byte {
+	out := []byte{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			out = append(out, s[i])
+			continue
+		}
+		if i+3 < len(s) && isDigits(s[i+1:i+4], base) {
+			n, err := strconv.ParseUint(s[i+1:i+4], base, 8)
+			if err == nil {
+				out = append(out, byte(n))
+				i += 3
+				continue
+			}
+		}
+		i++
+		out = append(out, s[i])
+	}
+	return out
+}
+
+func isDigits(s string, base int) bool {
+	for _, c := range s {
+		if c < '0' || int(c-'0') >= base {
+			return false
+		}
+	}
+	return true
+}
+
+// zoneFileName converts a Route53 domain name into master file syntax, where
+// escapes are decimal (\DDD) instead of octal.
+func zoneFileName(name string) string {
+	return convertName(name, 8, 10)
+}
+
+// route53Name converts a domain name in master file syntax into the one used
+// by Route53, which is lower case and uses octal escapes.
+func route53Name(name string) string {
+	return strings.ToLower(convertName(name, 10, 8))
+}
+
+// convertName rewrites the escapes in name from one base to another.
+func convertName(name string, from, to int) string {
+	labels := []string{}
+	label := []byte{}
+	for i := 0; i < len(name); i++ {
+		switch {
+		case name[i] == '.':
+			labels = append(labels, escapeLabel(label, to))
+			label = []byte{}
+		case name[i] == '\\' && i+3 < len(name) && isDigits(name[i+1:i+4], from):
+			label = append(label, decodeEscapes(name[i:i+4], from)...)
+			i += 3
+		case name[i] == '\\' && i+1 < len(name):
+			i++
+			label = append(label, name[i])
+		default:
+			label = append(label, name[i])
+		}
+	}
+	if len(label) > 0 {
+		labels = append(labels, escapeLabel(label, to))
+	}
+	return strings.Join(labels, ".") + "."
+}
+
+func escapeLabel(label []byte, base int) string {
+	var b strings.Builder
+	for _, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z',
+			c >= 'A' && c <= 'Z',
+			c >= '0' && c <= '9',
+			c == '-', c == '_', c == '*':
+			b.WriteByte(c)
+		default:
+			writeEscape(&b, c, base)
+		}
+	}
+	return b.String()
+}
+
+func writeEscape(b *strings.Builder, c byte, base int) {
+	if base == 8 {
+		fmt.Fprintf(b, "\\%03o", c)
+		return
+	}
+	fmt.Fprintf(b, "\\%03d", c)
+}
+
+// splitCharacterStrings splits a TXT value in its character strings,
+// honouring quotes, and returns each of them already unescaped.
+func splitCharacterStrings(value string, base int) (strs [][]byte) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "\"") {
+		return [][]byte{decodeEscapes(value, base)}
+	}
+	for len(value) > 0 {
+		value = strings.TrimLeft(value, " \t")
+		if !strings.HasPrefix(value, "\"") {
+			if value != "" {
+				strs = append(strs, decodeEscapes(value, base))
+			}
+			return
+		}
+		end := 1
+		for end < len(value) && value[end] != '"' {
+			if value[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end > len(value) {
+			end = len(value)
+		}
+		strs = append(strs, decodeEscapes(value[1:end], base))
+		if end < len(value) {
+			end++
+		}
+		value = value[end:]
+	}
+	return
+}
+
+// txtValue returns the Route53 value of a TXT record as one or more quoted
+// character strings in master file syntax, splitting those longer than 255
+// bytes.
+func txtValue(value string) string {
+	return convertTXT(value, 8, 10)
+}
+
+// route53TXT returns a TXT value in master file syntax as used by Route53.
+func route53TXT(value string) string {
+	return convertTXT(value, 10, 8)
+}
+
+func convertTXT(value string, from, to int) string {
+	quoted := []string{}
+	for _, s := range splitCharacterStrings(value, from) {
+		for len(s) > maxCharacterString {
+			quoted = append(
+				quoted,
+				quoteCharacterString(s[:maxCharacterString], to),
+			)
+			s = s[maxCharacterString:]
+		}
+		quoted = append(quoted, quoteCharacterString(s, to))
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteCharacterString(s []byte, base int) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range s {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			writeEscape(&b, c, base)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
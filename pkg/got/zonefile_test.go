@@ -0,0 +1,195 @@
+package got
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+var zonefileRecords = []*route53.ResourceRecordSet{
+	{
+		Name: aws.String("example.com."),
+		Type: aws.String("SOA"),
+		TTL:  aws.Int64(900),
+		ResourceRecords: NewResourceRecordList([]string{
+			"ns-1.awsdns-1.com. awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400",
+		}),
+	},
+	{
+		Name: aws.String("example.com."),
+		Type: aws.String("MX"),
+		TTL:  aws.Int64(300),
+		ResourceRecords: NewResourceRecordList([]string{
+			"10 mx1.example.com.",
+			"20 mx2.example.com.",
+		}),
+	},
+	{
+		Name: aws.String("\\052.example.com."),
+		Type: aws.String("CNAME"),
+		TTL:  aws.Int64(60),
+		ResourceRecords: NewResourceRecordList([]string{
+			"www.example.com.",
+		}),
+	},
+	{
+		Name: aws.String("txt.example.com."),
+		Type: aws.String("TXT"),
+		TTL:  aws.Int64(300),
+		ResourceRecords: NewResourceRecordList([]string{
+			"\"v=spf1 -all\"",
+			"unquoted \"value\"",
+			"\"caf\\351\"",
+		}),
+	},
+	{
+		Name: aws.String("alias.example.com."),
+		Type: aws.String("A"),
+		AliasTarget: &route53.AliasTarget{
+			DNSName:      aws.String("lb.elb.amazonaws.com."),
+			HostedZoneId: aws.String("Z35SXDOTRQ7X7K"),
+		},
+	},
+}
+
+func TestWriteZoneFile(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteZoneFile(&out, "example.com", zonefileRecords); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := []string{
+		"$ORIGIN example.com.",
+		"$TTL 900",
+		"@\t900\tIN\tSOA\tns-1.awsdns-1.com. awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400",
+		"@\t300\tIN\tMX\t10 mx1.example.com.",
+		"@\t300\tIN\tMX\t20 mx2.example.com.",
+		"*\t60\tIN\tCNAME\twww.example.com.",
+		"txt\t300\tIN\tTXT\t\"v=spf1 -all\"",
+		"txt\t300\tIN\tTXT\t\"unquoted \\\"value\\\"\"",
+		"txt\t300\tIN\tTXT\t\"caf\\233\"",
+		"; alias\tALIAS\tA\tlb.elb.amazonaws.com.\tZ35SXDOTRQ7X7K",
+		"",
+	}
+	if out.String() != strings.Join(expected, "\n") {
+		t.Errorf(
+			"Output doesn't match. Expected:\n%s\n-----\n%s",
+			strings.Join(expected, "\n"),
+			out.String(),
+		)
+	}
+}
+
+var txtValueTests = []struct {
+	in  string
+	out string
+}{
+	{"\"one\"", "\"one\""},
+	{"\"one\" \"two\"", "\"one\" \"two\""},
+	{"plain", "\"plain\""},
+	{"\"with \\\"quotes\\\"\"", "\"with \\\"quotes\\\"\""},
+	{
+		"\"" + strings.Repeat("a", 300) + "\"",
+		"\"" + strings.Repeat("a", 255) + "\" \"" + strings.Repeat("a", 45) + "\"",
+	},
+}
+
+func TestTxtValue(t *testing.T) {
+	for _, tt := range txtValueTests {
+		t.Run(tt.in, func(t *testing.T) {
+			if out := txtValue(tt.in); out != tt.out {
+				t.Errorf("Expected %s, received %s", tt.out, out)
+			}
+		})
+	}
+}
+
+var zonefileContents = `$ORIGIN example.com.
+$TTL 3600
+@	IN	SOA	ns1 hostmaster 1 7200 900 1209600 86400
+@	IN	NS	ns1
+@	300	IN	MX	10 mx1
+@	300	IN	MX	20 mx2
+*	60	IN	CNAME	www
+txt	IN	TXT	"v=spf1 -all"
+txt	IN	TXT	"caf\233" "two"
+long	IN	TXT	"` + strings.Repeat("a", 300) + `"
+`
+
+func TestParseZoneFile(t *testing.T) {
+	records, err := ParseZoneFile(strings.NewReader(zonefileContents), "example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := []struct {
+		name   string
+		typ    string
+		ttl    int64
+		values []string
+	}{
+		{"example.com.", "SOA", 3600, []string{
+			"ns1.example.com. hostmaster.example.com. 1 7200 900 1209600 86400",
+		}},
+		{"example.com.", "NS", 3600, []string{"ns1.example.com."}},
+		{"example.com.", "MX", 300, []string{
+			"10 mx1.example.com.",
+			"20 mx2.example.com.",
+		}},
+		{"*.example.com.", "CNAME", 60, []string{"www.example.com."}},
+		{"txt.example.com.", "TXT", 3600, []string{
+			"\"v=spf1 -all\"",
+			"\"caf\\351\" \"two\"",
+		}},
+		{"long.example.com.", "TXT", 3600, []string{
+			"\"" + strings.Repeat("a", 255) + "\" \"" + strings.Repeat("a", 45) + "\"",
+		}},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf(
+			"Expected %d record sets, received %d",
+			len(expected),
+			len(records),
+		)
+	}
+	for i, e := range expected {
+		rrs := records[i]
+		if *rrs.Name != e.name || *rrs.Type != e.typ || *rrs.TTL != e.ttl {
+			t.Errorf(
+				"Expected %s %s %d, received %s %s %d",
+				e.name,
+				e.typ,
+				e.ttl,
+				*rrs.Name,
+				*rrs.Type,
+				*rrs.TTL,
+			)
+		}
+		if len(rrs.ResourceRecords) != len(e.values) {
+			t.Errorf("Unexpected values for %s %s", e.name, e.typ)
+			continue
+		}
+		for j, value := range e.values {
+			if *rrs.ResourceRecords[j].Value != value {
+				t.Errorf(
+					"Expected value %s, received %s",
+					value,
+					*rrs.ResourceRecords[j].Value,
+				)
+			}
+		}
+	}
+}
+
+func TestParseZoneFileMixedTTLs(t *testing.T) {
+	contents := `$TTL 300
+www	IN	A	10.0.0.1
+www	60	IN	A	10.0.0.2
+`
+	_, err := ParseZoneFile(strings.NewReader(contents), "example.com")
+	expected := "record set www.example.com. A has records with TTLs 300 and 60"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, received %v", expected, err)
+	}
+}
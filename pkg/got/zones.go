@@ -0,0 +1,152 @@
+package got
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// ZoneSelector tells apart hosted zones sharing a name, like a public zone
+// and the private zones for the same domain.
+type ZoneSelector struct {
+	// Private, if not nil, selects only private or only public zones.
+	Private *bool
+	// VPCID, if not empty, selects only the private zones associated with
+	// the VPC.
+	VPCID string
+}
+
+// match returns whether the selector selects the hosted zone. A nil
+// selector selects every zone.
+func (s *ZoneSelector) match(
+	zone *route53.HostedZone,
+	svc route53iface.Route53API,
+) (bool, error) {
+	if s == nil {
+		return true, nil
+	}
+	private := isPrivateZone(zone)
+	if s.Private != nil && *s.Private != private {
+		return false, nil
+	}
+	if s.VPCID == "" {
+		return true, nil
+	}
+	if !private {
+		return false, nil
+	}
+	out, err := svc.GetHostedZone(&route53.GetHostedZoneInput{Id: zone.Id})
+	if err != nil {
+		return false, err
+	}
+	for _, vpc := range out.VPCs {
+		if aws.StringValue(vpc.VPCId) == s.VPCID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isPrivateZone returns whether the hosted zone is private.
+func isPrivateZone(zone *route53.HostedZone) bool {
+	return zone.Config != nil && aws.BoolValue(zone.Config.PrivateZone)
+}
+
+// describeZone returns the ID of the zone along with its visibility.
+func describeZone(zone *route53.HostedZone) string {
+	if isPrivateZone(zone) {
+		return *zone.Id + " (private)"
+	}
+	return *zone.Id + " (public)"
+}
+
+// FindHostedZone returns the hosted zone named exactly zoneName among those
+// selected. It fails if there is none, or if there is more than one.
+func FindHostedZone(
+	zoneName string,
+	selector *ZoneSelector,
+	svc route53iface.Route53API,
+) (zone *route53.HostedZone, err error) {
+	name := strings.ToLower(Fqdn(zoneName))
+	params := &route53.ListHostedZonesByNameInput{
+		DNSName:  aws.String(zoneName),
+		MaxItems: aws.String("100"),
+	}
+	matches := []*route53.HostedZone{}
+	for {
+		var resp *route53.ListHostedZonesByNameOutput
+		resp, err = svc.ListHostedZonesByName(params)
+		if err != nil {
+			return
+		}
+		past := false
+		for _, hz := range resp.HostedZones {
+			if strings.ToLower(Fqdn(*hz.Name)) != name {
+				past = true
+				break
+			}
+			var ok bool
+			if ok, err = selector.match(hz, svc); err != nil {
+				return
+			}
+			if ok {
+				matches = append(matches, hz)
+			}
+		}
+		if past || !aws.BoolValue(resp.IsTruncated) {
+			break
+		}
+		params.DNSName = resp.NextDNSName
+		params.HostedZoneId = resp.NextHostedZoneId
+	}
+	switch len(matches) {
+	case 0:
+		err = fmt.Errorf("no results for zone %s. Exiting", zoneName)
+	case 1:
+		zone = matches[0]
+	default:
+		ids := []string{}
+		for _, hz := range matches {
+			ids = append(ids, describeZone(hz))
+		}
+		err = fmt.Errorf(
+			"zone %s is ambiguous, it matches %s",
+			zoneName,
+			strings.Join(ids, ", "),
+		)
+	}
+	return
+}
+
+// GetHostedZones returns every hosted zone in the account among those
+// selected. It may issue more than one request as each returns a fixed
+// amount of zones at most.
+func GetHostedZones(
+	selector *ZoneSelector,
+	svc route53iface.Route53API,
+) (zones []*route53.HostedZone, err error) {
+	params := &route53.ListHostedZonesInput{}
+	for {
+		var resp *route53.ListHostedZonesOutput
+		resp, err = svc.ListHostedZones(params)
+		if err != nil {
+			return
+		}
+		for _, hz := range resp.HostedZones {
+			var ok bool
+			if ok, err = selector.match(hz, svc); err != nil {
+				return
+			}
+			if ok {
+				zones = append(zones, hz)
+			}
+		}
+		if !aws.BoolValue(resp.IsTruncated) {
+			return
+		}
+		params.Marker = resp.NextMarker
+	}
+}
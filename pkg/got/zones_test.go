@@ -0,0 +1,203 @@
+package got
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+)
+
+// zonesRoute53Client is a mock holding several hosted zones, returned in
+// pages of pageSize zones.
+type zonesRoute53Client struct {
+	route53iface.Route53API
+	zones    []*route53.HostedZone
+	vpcs     map[string][]string
+	pageSize int
+}
+
+func newHostedZone(id, name string, private bool) *route53.HostedZone {
+	return &route53.HostedZone{
+		Id:     aws.String(id),
+		Name:   aws.String(name),
+		Config: &route53.HostedZoneConfig{PrivateZone: aws.Bool(private)},
+	}
+}
+
+func newZonesRoute53Client() *zonesRoute53Client {
+	m := &zonesRoute53Client{
+		zones: []*route53.HostedZone{
+			newHostedZone("/hostedzone/Z1", "example.com.", false),
+			newHostedZone("/hostedzone/Z2", "example.com.", true),
+			newHostedZone("/hostedzone/Z3", "example.com.", true),
+			newHostedZone("/hostedzone/Z4", "example.net.", false),
+			newHostedZone("/hostedzone/Z5", "sub.example.com.", false),
+		},
+		vpcs: map[string][]string{
+			"/hostedzone/Z2": {"vpc-1"},
+			"/hostedzone/Z3": {"vpc-2", "vpc-3"},
+		},
+		pageSize: 2,
+	}
+	sort.SliceStable(m.zones, func(i, j int) bool {
+		return *m.zones[i].Name < *m.zones[j].Name
+	})
+	return m
+}
+
+// page returns the page of zones starting at start.
+func (m *zonesRoute53Client) page(start int) (zones []*route53.HostedZone, next int) {
+	next = start + m.pageSize
+	if next >= len(m.zones) {
+		return m.zones[start:], -1
+	}
+	return m.zones[start:next], next
+}
+
+func (m *zonesRoute53Client) ListHostedZonesByName(
+	params *route53.ListHostedZonesByNameInput,
+) (out *route53.ListHostedZonesByNameOutput, err error) {
+	start := len(m.zones)
+	for i, hz := range m.zones {
+		if Fqdn(*hz.Name) >= strings.ToLower(Fqdn(*params.DNSName)) &&
+			(params.HostedZoneId == nil || *hz.Id >= *params.HostedZoneId) {
+			start = i
+			break
+		}
+	}
+	zones, next := m.page(start)
+	out = &route53.ListHostedZonesByNameOutput{
+		HostedZones: zones,
+		IsTruncated: aws.Bool(next >= 0),
+	}
+	if next >= 0 {
+		out.NextDNSName = m.zones[next].Name
+		out.NextHostedZoneId = m.zones[next].Id
+	}
+	return
+}
+
+func (m *zonesRoute53Client) ListHostedZones(
+	params *route53.ListHostedZonesInput,
+) (out *route53.ListHostedZonesOutput, err error) {
+	start := 0
+	if params.Marker != nil {
+		start, _ = strconv.Atoi(*params.Marker)
+	}
+	zones, next := m.page(start)
+	out = &route53.ListHostedZonesOutput{
+		HostedZones: zones,
+		IsTruncated: aws.Bool(next >= 0),
+		NextMarker:  aws.String(strconv.Itoa(next)),
+	}
+	return
+}
+
+func (m *zonesRoute53Client) GetHostedZone(
+	params *route53.GetHostedZoneInput,
+) (out *route53.GetHostedZoneOutput, err error) {
+	out = &route53.GetHostedZoneOutput{}
+	for _, vpc := range m.vpcs[*params.Id] {
+		out.VPCs = append(out.VPCs, &route53.VPC{VPCId: aws.String(vpc)})
+	}
+	return
+}
+
+var findHostedZoneCases = []struct {
+	name     string
+	zone     string
+	selector *ZoneSelector
+	expected string
+	err      string
+}{
+	{
+		name:     "Unique name",
+		zone:     "example.net",
+		expected: "/hostedzone/Z4",
+	},
+	{
+		name:     "Exact match only",
+		zone:     "Sub.Example.com.",
+		expected: "/hostedzone/Z5",
+	},
+	{
+		name: "Ambiguous name",
+		zone: "example.com",
+		err:  "zone example.com is ambiguous, it matches /hostedzone/Z1 (public), /hostedzone/Z2 (private), /hostedzone/Z3 (private)",
+	},
+	{
+		name:     "Public zone",
+		zone:     "example.com",
+		selector: &ZoneSelector{Private: aws.Bool(false)},
+		expected: "/hostedzone/Z1",
+	},
+	{
+		name:     "Ambiguous private zone",
+		zone:     "example.com",
+		selector: &ZoneSelector{Private: aws.Bool(true)},
+		err:      "zone example.com is ambiguous, it matches /hostedzone/Z2 (private), /hostedzone/Z3 (private)",
+	},
+	{
+		name:     "Private zone by VPC",
+		zone:     "example.com",
+		selector: &ZoneSelector{VPCID: "vpc-3"},
+		expected: "/hostedzone/Z3",
+	},
+	{
+		name: "Missing zone",
+		zone: "example.org",
+		err:  "no results for zone example.org. Exiting",
+	},
+	{
+		name: "Similar name",
+		zone: "example",
+		err:  "no results for zone example. Exiting",
+	},
+}
+
+func TestFindHostedZone(t *testing.T) {
+	mockSvc := newZonesRoute53Client()
+	for _, tc := range findHostedZoneCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := FindHostedZone(tc.zone, tc.selector, mockSvc)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error %q, received %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %s", err)
+			}
+			if *out.Id != tc.expected {
+				t.Errorf("Expected zone %s, received %s", tc.expected, *out.Id)
+			}
+		})
+	}
+}
+
+func TestGetHostedZones(t *testing.T) {
+	mockSvc := newZonesRoute53Client()
+	out, err := GetHostedZones(nil, mockSvc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(out) != len(mockSvc.zones) {
+		t.Errorf("Expected %d zones, received %d", len(mockSvc.zones), len(out))
+	}
+	out, err = GetHostedZones(&ZoneSelector{Private: aws.Bool(false)}, mockSvc)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(out) != 3 {
+		t.Errorf("Expected 3 public zones, received %d", len(out))
+	}
+	out, err = GetHostedZones(&ZoneSelector{VPCID: "vpc-1"}, mockSvc)
+	if err != nil || len(out) != 1 || *out[0].Id != "/hostedzone/Z2" {
+		t.Errorf("Unexpected zones for VPC %v, %v", out, err)
+	}
+}
@@ -0,0 +1,130 @@
+package roosa
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// graphEdge is a CNAME reference from a record to the one it points at.
+type graphEdge struct {
+	from, to *Node
+}
+
+// walk returns every node in the reference trees, in the order they're
+// written, along with the references among them. Nodes with more than one
+// parent are returned once.
+func (rtl *ReferenceTreeList) walk() (nodes []*Node, edges []graphEdge) {
+	seen := map[*Node]bool{}
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if seen[node] {
+			return
+		}
+		seen[node] = true
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			edges = append(edges, graphEdge{from: child, to: node})
+			visit(child)
+		}
+	}
+	for _, root := range rtl.roots() {
+		visit(root)
+	}
+	return
+}
+
+// isAddress returns whether the node is a root holding addresses, an A or
+// AAAA record, rather than a CNAME pointing out of the zone.
+func isAddress(n *Node) bool {
+	return n.IsRoot() && *n.content.Type != "CNAME"
+}
+
+// DOT returns a graph in DOT format of the reference trees, with a node
+// per record and an edge per CNAME reference, from the record to the one
+// it points at. A and AAAA records are drawn as filled boxes.
+func (rtl *ReferenceTreeList) DOT() (string, error) {
+	nodes, edges := rtl.walk()
+	g := gographviz.NewEscape()
+	if err := g.SetName("G"); err != nil {
+		return "", err
+	}
+	if err := g.SetDir(true); err != nil {
+		return "", err
+	}
+	if err := g.AddAttr("G", "rankdir", "LR"); err != nil {
+		return "", err
+	}
+	for _, node := range nodes {
+		attrs := map[string]string{
+			"label": fmt.Sprintf(
+				"%s\\n%s %s",
+				*node.content.Name,
+				*node.content.Type,
+				strings.Join(recordValues(node.content), ", "),
+			),
+		}
+		if isAddress(node) {
+			attrs["shape"] = "box"
+			attrs["style"] = "filled"
+			attrs["fillcolor"] = "lightblue"
+		}
+		if err := g.AddNode("G", nodeID(node), attrs); err != nil {
+			return "", err
+		}
+	}
+	for _, edge := range edges {
+		if err := g.AddEdge(
+			nodeID(edge.from),
+			nodeID(edge.to),
+			true,
+			nil,
+		); err != nil {
+			return "", err
+		}
+	}
+	return g.String(), nil
+}
+
+// Mermaid returns a flowchart in Mermaid syntax of the reference trees,
+// with a node per record and an edge per CNAME reference, from the record
+// to the one it points at. A and AAAA records are drawn as filled boxes.
+func (rtl *ReferenceTreeList) Mermaid() string {
+	nodes, edges := rtl.walk()
+	ids := map[*Node]string{}
+	addresses := []string{}
+	output := "flowchart LR\n"
+	for i, node := range nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+		label := mermaidLabel(fmt.Sprintf(
+			"%s<br/>%s %s",
+			*node.content.Name,
+			*node.content.Type,
+			strings.Join(recordValues(node.content), ", "),
+		))
+		shape := "(\"%s\")"
+		if isAddress(node) {
+			shape = "[\"%s\"]"
+			addresses = append(addresses, ids[node])
+		}
+		output += fmt.Sprintf("    %s"+shape+"\n", ids[node], label)
+	}
+	for _, edge := range edges {
+		output += fmt.Sprintf("    %s --> %s\n", ids[edge.from], ids[edge.to])
+	}
+	output += "    classDef address fill:lightblue,stroke:#333\n"
+	if len(addresses) > 0 {
+		output += fmt.Sprintf(
+			"    class %s address\n",
+			strings.Join(addresses, ","),
+		)
+	}
+	return output
+}
+
+// mermaidLabel escapes the characters of label Mermaid doesn't allow in
+// quoted labels.
+func mermaidLabel(label string) string {
+	return strings.ReplaceAll(label, "\"", "#quot;")
+}
@@ -0,0 +1,72 @@
+package roosa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+func graphRecords() (records []*route53.ResourceRecordSet) {
+	for _, record := range []string{
+		"root.example.com. A 127.0.0.1",
+		"root-son.example.com. CNAME root.example.com",
+		"root-grandson.example.com. CNAME root-son.example.com",
+		"v6.example.com. AAAA ::1,::2",
+		"out.example.com. CNAME \"quoted\".example.net",
+	} {
+		fields := strings.Fields(record)
+		records = append(records, &route53.ResourceRecordSet{
+			Name: aws.String(fields[0]),
+			Type: aws.String(fields[1]),
+		})
+		for _, value := range strings.Split(fields[2], ",") {
+			records[len(records)-1].ResourceRecords = append(
+				records[len(records)-1].ResourceRecords,
+				&route53.ResourceRecord{Value: aws.String(value)},
+			)
+		}
+	}
+	return
+}
+
+func TestReferenceTreeListDOT(t *testing.T) {
+	out, err := NewReferenceTreeList(graphRecords()).DOT()
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := `digraph G {
+	rankdir=LR;
+	"root-son.example.com. CNAME"->"root.example.com. A";
+	"root-grandson.example.com. CNAME"->"root-son.example.com. CNAME";
+	"out.example.com. CNAME" [ label="out.example.com.\nCNAME &#34;quoted&#34;.example.net" ];
+	"root-grandson.example.com. CNAME" [ label="root-grandson.example.com.\nCNAME root-son.example.com" ];
+	"root-son.example.com. CNAME" [ label="root-son.example.com.\nCNAME root.example.com" ];
+	"root.example.com. A" [ fillcolor=lightblue, label="root.example.com.\nA 127.0.0.1", shape=box, style=filled ];
+	"v6.example.com. AAAA" [ fillcolor=lightblue, label="v6.example.com.\nAAAA ::1, ::2", shape=box, style=filled ];
+
+}
+`
+	if out != expected {
+		t.Errorf("Unexpected DOT output:\n%s", out)
+	}
+}
+
+func TestReferenceTreeListMermaid(t *testing.T) {
+	out := NewReferenceTreeList(graphRecords()).Mermaid()
+	expected := `flowchart LR
+    n0("out.example.com.<br/>CNAME #quot;quoted#quot;.example.net")
+    n1["root.example.com.<br/>A 127.0.0.1"]
+    n2("root-son.example.com.<br/>CNAME root.example.com")
+    n3("root-grandson.example.com.<br/>CNAME root-son.example.com")
+    n4["v6.example.com.<br/>AAAA ::1, ::2"]
+    n2 --> n1
+    n3 --> n2
+    classDef address fill:lightblue,stroke:#333
+    class n1,n4 address
+`
+	if out != expected {
+		t.Errorf("Unexpected Mermaid output:\n%s", out)
+	}
+}
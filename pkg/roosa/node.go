@@ -1,7 +1,9 @@
 package roosa
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/service/route53"
@@ -48,3 +50,66 @@ func (n *Node) String() (output string) {
 	}
 	return
 }
+
+// nodeJSON is the JSON form of a Node.
+type nodeJSON struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Values   []string    `json:"values"`
+	Depth    int         `json:"depth"`
+	Children []*nodeJSON `json:"children"`
+}
+
+// MarshalJSON returns the node and its children in JSON format, along with
+// their depth in the tree, 0 for roots.
+func (n *Node) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.toJSON(0))
+}
+
+func (n *Node) toJSON(depth int) *nodeJSON {
+	out := &nodeJSON{
+		Name:     *n.content.Name,
+		Type:     *n.content.Type,
+		Values:   recordValues(n.content),
+		Depth:    depth,
+		Children: []*nodeJSON{},
+	}
+	for _, child := range n.children {
+		out.Children = append(out.Children, child.toJSON(depth+1))
+	}
+	return out
+}
+
+// sortNodes sorts the nodes by name, type and set identifier.
+func sortNodes(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodeID(nodes[i]) < nodeID(nodes[j])
+	})
+}
+
+// sortChildren sorts the children of the node and its descendants.
+func (n *Node) sortChildren() {
+	sortNodes(n.children)
+	for _, child := range n.children {
+		child.sortChildren()
+	}
+}
+
+// nodeID returns the name, type and set identifier of the record of the
+// node, which identify it.
+func nodeID(n *Node) string {
+	id := *n.content.Name + " " + *n.content.Type
+	if n.content.SetIdentifier != nil {
+		id += " " + *n.content.SetIdentifier
+	}
+	return id
+}
+
+// recordValues returns the values of the record.
+func recordValues(rrs *route53.ResourceRecordSet) (values []string) {
+	values = []string{}
+	for _, rr := range rrs.ResourceRecords {
+		values = append(values, *rr.Value)
+	}
+	return
+}
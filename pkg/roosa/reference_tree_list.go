@@ -1,6 +1,7 @@
 package roosa
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -28,6 +29,7 @@ func GetResourceRecordSet(
 		if *resp.IsTruncated {
 			params.StartRecordName = resp.NextRecordName
 			params.StartRecordType = resp.NextRecordType
+			params.StartRecordIdentifier = resp.NextRecordIdentifier
 		}
 		respIsTruncated = *resp.IsTruncated
 		resourceRecordSet = append(resourceRecordSet, resp.ResourceRecordSets...)
@@ -101,27 +103,49 @@ func NewReferenceTreeList(
 }
 
 // GetReferenceTrees builds and returns the reference trees among the
-// ReferenceTreeList records attribute.
+// ReferenceTreeList records attribute. Children are sorted by name, type
+// and set identifier.
 func (rtl *ReferenceTreeList) GetReferenceTrees() map[string][]*Node {
 	rtl.fill()
 	rtl.compact()
+	for _, tree := range rtl.lookup {
+		for _, node := range tree {
+			node.sortChildren()
+		}
+	}
 	return rtl.lookup
 }
 
-// String returns a string representing ReferenceTreeList contents.
-func (rtl *ReferenceTreeList) String() string {
+// roots returns the roots of the reference trees sorted by name, type and
+// set identifier, building the trees if needed.
+func (rtl *ReferenceTreeList) roots() (roots []*Node) {
 	if rtl.lookup == nil {
 		rtl.GetReferenceTrees()
 	}
-	output := ""
+	roots = []*Node{}
 	for _, tree := range rtl.lookup {
-		for _, node := range tree {
-			output += fmt.Sprintf("%v\n", node)
-		}
+		roots = append(roots, tree...)
+	}
+	sortNodes(roots)
+	return
+}
+
+// String returns a string representing ReferenceTreeList contents, with
+// the trees sorted by the name, type and set identifier of their roots.
+func (rtl *ReferenceTreeList) String() string {
+	output := ""
+	for _, node := range rtl.roots() {
+		output += fmt.Sprintf("%v\n", node)
 	}
 	return output
 }
 
+// MarshalJSON returns the reference trees in JSON format, as a list of
+// their roots sorted as String does.
+func (rtl *ReferenceTreeList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rtl.roots())
+}
+
 // fill fills the referral lookup table with the base records.
 func (rtl *ReferenceTreeList) fill() {
 	rtl.lookup = map[string][]*Node{}
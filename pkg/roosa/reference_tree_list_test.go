@@ -1,12 +1,16 @@
 package roosa
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+
+	"github.com/poka-yoke/spaceflight/internal/test/mocks"
 )
 
 var one = "one.example.com"
@@ -268,3 +272,79 @@ func TestReferenceTreeListString(t *testing.T) {
 		)
 	}
 }
+
+func TestGetResourceRecordSetWithFake(t *testing.T) {
+	svc := mocks.NewRoute53Client()
+	svc.PageSize = 2
+	svc.AddHostedZone("example.org")
+	zoneID := svc.AddHostedZone("example.com")
+	list := []*route53.ResourceRecordSet{}
+	for _, id := range []string{"blue", "green", "red"} {
+		list = append(list, &route53.ResourceRecordSet{
+			Name:          aws.String("api.example.com."),
+			Type:          aws.String("CNAME"),
+			TTL:           aws.Int64(60),
+			SetIdentifier: aws.String(id),
+			Weight:        aws.Int64(1),
+			ResourceRecords: []*route53.ResourceRecord{
+				{Value: aws.String(id + ".example.com.")},
+			},
+		})
+	}
+	svc.AddRecordSets(zoneID, list...)
+
+	if id := GetZoneID("example.com", svc); id != zoneID {
+		t.Errorf("Expected zone %s, received %s", zoneID, id)
+	}
+	out := GetResourceRecordSet(zoneID, svc)
+	// The NS and SOA record sets go first.
+	if len(out) != 2+len(list) {
+		t.Fatalf("Expected %d record sets, received %d", 2+len(list), len(out))
+	}
+	for i, rrs := range list {
+		if *out[2+i].SetIdentifier != *rrs.SetIdentifier {
+			t.Errorf("Expected %s, received %s", rrs, out[2+i])
+		}
+	}
+}
+
+func TestReferenceTreeListStringSorted(t *testing.T) {
+	expected := strings.Join([]string{
+		"example.com. A 10.10.10.10",
+		"multiple-a.example.com. A 127.0.0.1, 127.0.0.2, 127.0.0.3",
+		"root.example.com. A 127.0.0.1",
+		"\troot-son-sibling.example.com. CNAME root.example.com",
+		"\troot-son.example.com. CNAME root.example.com",
+		"\t\troot-grandson.example.com. CNAME root-son.example.com",
+		"\tservice1.example.com. CNAME root.example.com",
+		"root2.example.com. A 127.0.0.2",
+		"\tservice1.example.com. CNAME root2.example.com",
+		"test.example.com. CNAME test.example2.com",
+		"",
+	}, "\n")
+	// Maps are iterated in a different order every time, so the output is
+	// checked a few times.
+	for i := 0; i < 10; i++ {
+		output := NewReferenceTreeList(generateRoute53RRS()).String()
+		if output != expected {
+			t.Fatalf("Unexpected output:\n%v", output)
+		}
+	}
+}
+
+func TestReferenceTreeListJSON(t *testing.T) {
+	output, err := json.Marshal(NewReferenceTreeList(graphRecords()))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	expected := `[` +
+		`{"name":"out.example.com.","type":"CNAME","values":["\"quoted\".example.net"],"depth":0,"children":[]},` +
+		`{"name":"root.example.com.","type":"A","values":["127.0.0.1"],"depth":0,"children":[` +
+		`{"name":"root-son.example.com.","type":"CNAME","values":["root.example.com"],"depth":1,"children":[` +
+		`{"name":"root-grandson.example.com.","type":"CNAME","values":["root-son.example.com"],"depth":2,"children":[]}]}]},` +
+		`{"name":"v6.example.com.","type":"AAAA","values":["::1","::2"],"depth":0,"children":[]}` +
+		`]`
+	if string(output) != expected {
+		t.Errorf("Unexpected JSON output:\n%s", output)
+	}
+}